@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	jsoniter "github.com/json-iterator/go"
+	"log"
+	"os"
+	"path/filepath"
+	"storex/db"
+	"storex/importer"
+)
+
+// Bulk imports assets from a CSV or XLSX file, e.g.
+//
+//	go run ./cmd/import -file laptops.xlsx -created-by <user_id> -dry-run
+func main() {
+	file := flag.String("file", "", "path to the .csv or .xlsx file")
+	createdBy := flag.String("created-by", "", "id of the user recorded as creator of the assets")
+	dryRun := flag.Bool("dry-run", false, "only validate the file")
+	batchSize := flag.Int("batch-size", 0, "commit every n rows, 0 imports everything in one transaction")
+	importID := flag.String("import-id", "", "resume an earlier batched import of the same file")
+	flag.Parse()
+
+	if *file == "" || (*createdBy == "" && !*dryRun) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("failed to read file: %v", err)
+	}
+
+	err = db.InitDB()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer db.DB.Close()
+
+	report, err := importer.Run(data, importer.Options{
+		FileName:  filepath.Base(*file),
+		CreatedBy: *createdBy,
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+		ImportID:  *importID,
+	})
+	if err != nil {
+		log.Fatalf("import failed: %v", err)
+	}
+
+	out, _ := jsoniter.MarshalIndent(report, "", "  ")
+	os.Stdout.Write(append(out, '\n'))
+
+	if report.Status != "completed" && report.Status != "validated" {
+		os.Exit(1)
+	}
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"storex/db"
+	"storex/handlers"
+	"storex/jobs"
+	"storex/notifier"
 	"storex/routes"
 )
 
 func main() {
 	err := db.InitDB()
 	if err != nil {
-		log.Fatalf(err.Error())
+		log.Fatal(err.Error())
 	}
 	defer func() {
 		err = db.DB.Close()
@@ -18,6 +22,15 @@ func main() {
 		}
 	}()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := notifier.FromEnv()
+	handlers.Notifier = n
+
+	// background jobs
+	jobs.StartWarrantyReminders(ctx, n, jobs.WarrantyReminderConfigFromEnv())
+
 	//routes setup here
 	routes.Setup()
 
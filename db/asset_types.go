@@ -0,0 +1,202 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"storex/models"
+	"storex/utils"
+)
+
+var ErrUnknownAssetType = errors.New("unsupported asset type")
+
+func GetAssetType(name string) (*models.AssetTypeDefinition, error) {
+	query := `
+		SELECT name, fields, built_in, created_at, depreciation_method, useful_life_months, declining_rate
+		FROM asset_types
+		WHERE name = $1 AND archived_at IS NULL
+	`
+
+	var def models.AssetTypeDefinition
+	var fields []byte
+	err := DB.QueryRow(query, name).Scan(&def.Name, &fields, &def.BuiltIn, &def.CreatedAt,
+		&def.Method, &def.UsefulLifeMonths, &def.DecliningRate)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAssetType, name)
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(fields, &def.Fields); err != nil {
+		return nil, fmt.Errorf("corrupt field definition for asset type %s: %w", name, err)
+	}
+	return &def, nil
+}
+
+func ListAssetTypes() ([]models.AssetTypeDefinition, error) {
+	rows, err := DB.Query(`
+		SELECT name, fields, built_in, created_at, depreciation_method, useful_life_months, declining_rate
+		FROM asset_types
+		WHERE archived_at IS NULL
+		ORDER BY built_in DESC, name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var defs []models.AssetTypeDefinition
+	for rows.Next() {
+		var def models.AssetTypeDefinition
+		var fields []byte
+		err := rows.Scan(&def.Name, &fields, &def.BuiltIn, &def.CreatedAt,
+			&def.Method, &def.UsefulLifeMonths, &def.DecliningRate)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(fields, &def.Fields); err != nil {
+			return nil, fmt.Errorf("corrupt field definition for asset type %s: %w", def.Name, err)
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+func CreateAssetType(req *models.CreateAssetTypeRequest, authUserID string) (int64, error) {
+	fields, err := json.Marshal(req.Fields)
+	if err != nil {
+		return 0, err
+	}
+
+	// an archived type with the same name is brought back with the new fields
+	res, err := DB.Exec(`
+		INSERT INTO asset_types (name, fields, created_by, depreciation_method, useful_life_months, declining_rate)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (name) DO UPDATE SET
+			fields = EXCLUDED.fields,
+			depreciation_method = EXCLUDED.depreciation_method,
+			useful_life_months = EXCLUDED.useful_life_months,
+			declining_rate = EXCLUDED.declining_rate,
+			updated_at = CURRENT_TIMESTAMP,
+			updated_by = EXCLUDED.created_by,
+			archived_at = NULL,
+			archived_by = NULL
+		WHERE asset_types.archived_at IS NOT NULL
+	`, req.Name, fields, authUserID, req.Method, req.UsefulLifeMonths, req.DecliningRate)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// UpdateAssetType replaces the fields of a custom type and the policy values that are set.
+// Built-in types only accept policy changes, so their fields must be left out.
+func UpdateAssetType(tx *sql.Tx, name string, req *models.UpdateAssetTypeRequest, authUserID string) (int64, error) {
+	var fields *string
+	if req.Fields != nil {
+		raw, err := json.Marshal(req.Fields)
+		if err != nil {
+			return 0, err
+		}
+		encoded := string(raw)
+		fields = &encoded
+	}
+
+	res, err := tx.Exec(`
+		UPDATE asset_types SET
+			fields = COALESCE($1, fields),
+			depreciation_method = COALESCE($2, depreciation_method),
+			useful_life_months = COALESCE($3, useful_life_months),
+			declining_rate = COALESCE($4, declining_rate),
+			updated_at = CURRENT_TIMESTAMP,
+			updated_by = $5
+		WHERE name = $6 AND ($1::jsonb IS NULL OR built_in = false) AND archived_at IS NULL
+	`, fields, req.Method, req.UsefulLifeMonths, req.DecliningRate, authUserID, name)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SpecsNotMatching checks what is stored for an asset type against a new field list: the specs of
+// its assets as they are, model defaults included, and the defaults of its models on their own.
+// It returns a message per mismatch, at most limit of them.
+func SpecsNotMatching(tx *sql.Tx, assetType string, fields []models.SpecField, limit int) ([]string, error) {
+	var mismatches []string
+
+	rows, err := tx.Query(`
+		SELECT m.name, m.default_specs FROM asset_models m WHERE m.asset_type = $1 ORDER BY m.name
+	`, assetType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() && len(mismatches) < limit {
+		var name string
+		var raw []byte
+		if err := rows.Scan(&name, &raw); err != nil {
+			return nil, err
+		}
+		var specs map[string]interface{}
+		if err := json.Unmarshal(raw, &specs); err != nil {
+			return nil, fmt.Errorf("corrupt default specs of model %s: %w", name, err)
+		}
+		if _, err := utils.ValidateSpecs(fields, specs, true); err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("model %s: %v", name, err))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	rows, err = tx.Query(`
+		SELECT a.serial_no, `+effectiveSpecsSQL+`
+		FROM assets a
+		JOIN asset_models m ON m.id = a.model_id
+		JOIN asset_specs sp ON sp.id = a.specs_id
+		WHERE m.asset_type = $1
+		ORDER BY a.serial_no
+	`, assetType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() && len(mismatches) < limit {
+		var serialNo string
+		var raw []byte
+		if err := rows.Scan(&serialNo, &raw); err != nil {
+			return nil, err
+		}
+		var specs map[string]interface{}
+		if err := json.Unmarshal(raw, &specs); err != nil {
+			return nil, fmt.Errorf("corrupt specs of asset %s: %w", serialNo, err)
+		}
+		if _, err := utils.ValidateSpecs(fields, specs, false); err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("asset %s: %v", serialNo, err))
+		}
+	}
+	return mismatches, rows.Err()
+}
+
+func NumberOfModelsByAssetType(tx *sql.Tx, name string) (int, error) {
+	count := 0
+	err := tx.QueryRow(`SELECT COUNT(*) FROM asset_models WHERE asset_type = $1`, name).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func ArchiveAssetType(tx *sql.Tx, name string, authUserID string) (int64, error) {
+	res, err := tx.Exec(`
+		UPDATE asset_types SET archived_at = NOW(), archived_by = $2
+		WHERE name = $1 AND built_in = false AND archived_at IS NULL
+	`, name, authUserID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
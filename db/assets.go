@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"github.com/lib/pq"
 	"log"
+	"slices"
 	"storex/models"
+	"storex/utils"
 	"strings"
 	"time"
 )
@@ -65,129 +67,45 @@ func GetOrCreateBrand(tx *sql.Tx, brandName string) (string, error) {
 	return brandID, nil
 }
 
-func InsertSpecsAndReturnID(tx *sql.Tx, assetType string, specs interface{}) (string, error) {
-	var query string
-	var args []any
-	var specsID string
-
-	switch assetType {
-	case "laptop":
-		// Re-marshal into JSON
-		specsBytes, err := json.Marshal(specs)
-		if err != nil {
-			return "", fmt.Errorf("failed to re-marshal specs: %w", err)
-		}
-
-		var s models.LaptopSpecs
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return "", fmt.Errorf("failed to unmarshal laptop specs: %w", err)
-		}
-		query = `INSERT INTO laptop_specs (processor, ram_gb, storage_gb, storage_type, screen_size_inch, has_charger) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
-		args = []any{s.Processor, s.RAMGB, s.StorageGB, s.StorageType, s.ScreenSizeInch, s.HasCharger}
+// effectiveSpecsSQL is the specs of an asset as it is, its own values on top of the defaults of its model.
+// It expects asset_models m and asset_specs sp in the query.
+const effectiveSpecsSQL = `(m.default_specs || sp.specs)`
 
-	case "mouse":
-		// Re-marshal into JSON
-		specsBytes, err := json.Marshal(specs)
-		if err != nil {
-			return "", fmt.Errorf("failed to re-marshal specs: %w", err)
-		}
-
-		var s models.MouseSpecs
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return "", fmt.Errorf("failed to unmarshal mouse specs: %w", err)
-		}
-		query = `INSERT INTO mouse_specs (type, dpi, number_of_buttons) VALUES ($1, $2, $3) RETURNING id`
-		args = []any{s.Type, s.DPI, s.NumberOfButtons}
-
-	case "monitor":
-		// Re-marshal into JSON
-		specsBytes, err := json.Marshal(specs)
-		if err != nil {
-			return "", fmt.Errorf("failed to re-marshal specs: %w", err)
-		}
-
-		var s models.MonitorSpecs
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return "", fmt.Errorf("failed to unmarshal monitor specs: %w", err)
-		}
-		query = `INSERT INTO monitor_specs (screen_size_inch, resolution, refresh_rate, panel_type) VALUES ($1, $2, $3, $4) RETURNING id`
-		args = []any{s.ScreenSizeInch, s.Resolution, s.RefreshRate, s.PanelType}
-
-	case "mobile":
-		// Re-marshal into JSON
-		specsBytes, err := json.Marshal(specs)
-		if err != nil {
-			return "", fmt.Errorf("failed to re-marshal specs: %w", err)
-		}
-
-		var s models.MobileSpecs
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return "", fmt.Errorf("failed to unmarshal mobile specs: %w", err)
-		}
-		query = `INSERT INTO mobile_specs (os, ram_gb, storage_gb, has_dual_sim) VALUES ($1, $2, $3, $4) RETURNING id`
-		args = []any{s.OS, s.RAMGB, s.StorageGB, s.HasDualSIM}
-
-	case "sim":
-		// Re-marshal into JSON
-		specsBytes, err := json.Marshal(specs)
-		if err != nil {
-			return "", fmt.Errorf("failed to re-marshal specs: %w", err)
-		}
-
-		var s models.SIMSpecs
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return "", fmt.Errorf("failed to unmarshal sim specs: %w", err)
-		}
-		query = `INSERT INTO sim_specs (carrier, phone_number, data_limit_gb) VALUES ($1, $2, $3) RETURNING id`
-		args = []any{s.Carrier, s.PhoneNumber, s.DataLimitGB}
-
-	case "hard_disk":
-		// Re-marshal into JSON
-		specsBytes, err := json.Marshal(specs)
-		if err != nil {
-			return "", fmt.Errorf("failed to re-marshal specs: %w", err)
-		}
-
-		var s models.HardDiskSpecs
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return "", fmt.Errorf("failed to unmarshal hard-disk specs: %w", err)
-		}
-		query = `INSERT INTO hard_disk_specs (capacity_gb, type) VALUES ($1, $2) RETURNING id`
-		args = []any{s.CapacityGB, s.Type}
+// InsertSpecsAndReturnID validates specs against the asset type registry and stores them.
+// The asset inherits the default specs of its model, only the values that differ are stored.
+func InsertSpecsAndReturnID(tx *sql.Tx, modelID string, assetType string, specs interface{}) (string, error) {
+	def, err := GetAssetType(assetType)
+	if err != nil {
+		return "", err
+	}
 
-	case "pen_drive":
-		// Re-marshal into JSON
-		specsBytes, err := json.Marshal(specs)
-		if err != nil {
-			return "", fmt.Errorf("failed to re-marshal specs: %w", err)
-		}
+	raw, err := utils.SpecsToMap(specs)
+	if err != nil {
+		return "", err
+	}
 
-		var s models.PenDriveSpecs
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return "", fmt.Errorf("failed to unmarshal pen-drive specs: %w", err)
-		}
-		query = `INSERT INTO pen_drive_specs (capacity_gb, usb_version) VALUES ($1, $2) RETURNING id`
-		args = []any{s.CapacityGB, s.USBVersion}
+	overrides, err := utils.ValidateSpecs(def.Fields, raw, true)
+	if err != nil {
+		return "", err
+	}
 
-	case "accessories":
-		// Re-marshal into JSON
-		specsBytes, err := json.Marshal(specs)
-		if err != nil {
-			return "", fmt.Errorf("failed to re-marshal specs: %w", err)
-		}
+	defaults, err := GetModelDefaultSpecs(tx, modelID)
+	if err != nil {
+		return "", err
+	}
 
-		var s models.AccessorySpecs
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return "", fmt.Errorf("failed to unmarshal accessory specs: %w", err)
-		}
-		query = `INSERT INTO accessory_specs (name, description, compatible_with) VALUES ($1, $2, $3) RETURNING id`
-		args = []any{s.Name, s.Description, s.CompatibleWith}
+	// required fields may come from the model
+	if _, err := utils.ValidateSpecs(def.Fields, utils.MergeSpecs(defaults, overrides), false); err != nil {
+		return "", err
+	}
 
-	default:
-		return "", fmt.Errorf("unsupported asset type: %s", assetType)
+	specsBytes, err := json.Marshal(utils.SpecOverrides(defaults, overrides))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal specs: %w", err)
 	}
 
-	err := tx.QueryRow(query, args...).Scan(&specsID)
+	var specsID string
+	err = tx.QueryRow(`INSERT INTO asset_specs (asset_type, specs) VALUES ($1, $2) RETURNING id`, assetType, specsBytes).Scan(&specsID)
 	if err != nil {
 		return "", fmt.Errorf("failed to insert %s specs: %w", assetType, err)
 	}
@@ -195,13 +113,29 @@ func InsertSpecsAndReturnID(tx *sql.Tx, assetType string, specs interface{}) (st
 	return specsID, nil
 }
 
+func GetSpecsByID(specsID string) (map[string]interface{}, error) {
+	var specsBytes []byte
+	err := DB.QueryRow(`SELECT specs FROM asset_specs WHERE id = $1`, specsID).Scan(&specsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs map[string]interface{}
+	if err := json.Unmarshal(specsBytes, &specs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal specs: %w", err)
+	}
+	return specs, nil
+}
+
 func InsertAssetAndReturnID(tx *sql.Tx, modelID string, specsID string, req *models.CreateAssetRequest, authUserID string) (string, error) {
 	query := `
 		INSERT INTO assets (
 			model_id, specs_id, serial_no, owned_by,
 			purchased_date, warranty_start_date, warranty_exp_date,
-			created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			created_by,
+			purchase_cost, currency, salvage_value,
+			depreciation_method, useful_life_months, declining_rate
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, 'INR'), COALESCE($11, 0), $12, $13, $14)
 		RETURNING id
 	`
 
@@ -215,6 +149,12 @@ func InsertAssetAndReturnID(tx *sql.Tx, modelID string, specsID string, req *mod
 		req.WarrantyStartDate,
 		req.WarrantyExpDate,
 		authUserID,
+		req.PurchaseCost,
+		req.Currency,
+		req.SalvageValue,
+		req.Method,
+		req.UsefulLifeMonths,
+		req.DecliningRate,
 	).Scan(&assetID)
 
 	if err != nil {
@@ -223,11 +163,41 @@ func InsertAssetAndReturnID(tx *sql.Tx, modelID string, specsID string, req *mod
 	return assetID, nil
 }
 
+// InsertAssetFromRequest runs the model -> specs -> asset -> status chain shared by CreateAsset and the importer.
+// Unknown catalog entries, bad specs and unknown asset types come back as their sentinel errors.
+func InsertAssetFromRequest(tx *sql.Tx, req *models.CreateAssetRequest, authUserID string) (string, error) {
+	// Brand and model come from the catalog, created on the fly unless strict mode is on
+	modelID, err := ResolveModel(tx, &req.Model, utils.CatalogStrictMode())
+	if err != nil {
+		return "", err
+	}
+
+	specsID, err := InsertSpecsAndReturnID(tx, modelID, req.Model.AssetType, req.Specs)
+	if err != nil {
+		return "", err
+	}
+
+	assetID, err := InsertAssetAndReturnID(tx, modelID, specsID, req, authUserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert asset %s: %w", req.SerialNo, err)
+	}
+
+	if err := InsertAssetStatus(tx, assetID, "available"); err != nil {
+		return "", err
+	}
+	if req.LocationID != nil {
+		if err := SetActiveStatusLocation(tx, assetID, *req.LocationID); err != nil {
+			return "", err
+		}
+	}
+	return assetID, nil
+}
+
 func InsertAssetStatus(tx *sql.Tx, assetID string, status string) error {
 
 	statusInsertQuery := `
-    INSERT INTO asset_status (asset_id, status)
-    VALUES ($1, $2)
+    INSERT INTO asset_status (asset_id, status, location_id)
+    VALUES ($1, $2, ` + lastStockedLocationSQL + `)
 `
 	_, err := tx.Exec(statusInsertQuery, assetID, status)
 	if err != nil {
@@ -236,23 +206,169 @@ func InsertAssetStatus(tx *sql.Tx, assetID string, status string) error {
 	return nil
 }
 
+// AssetSortFields are what the asset listing can be sorted on
+var AssetSortFields = []models.SortField{
+	{Name: "created_at", Column: "a.created_at", Type: "TIMESTAMPTZ"},
+	{Name: "purchased_date", Column: "a.purchased_date", Type: "TIMESTAMPTZ"},
+	{Name: "serial_no", Column: "a.serial_no", Type: "TEXT"},
+	{Name: "brand", Column: "b.name", Type: "TEXT"},
+	{Name: "model", Column: "m.name", Type: "TEXT"},
+	{Name: "asset_type", Column: "m.asset_type", Type: "TEXT"},
+	{Name: "status", Column: "COALESCE(s.status::TEXT, '')", Type: "TEXT"},
+}
+
+var defaultAssetSort = []models.SortKey{{SortField: AssetSortFields[0], Desc: true}}
+
+// assetListJoins are the tables of the asset listing, shared with its count
+const assetListJoins = `
+			FROM assets a
+			JOIN asset_models m ON a.model_id = m.id
+			JOIN asset_brands b ON m.brand_id = b.id
+			JOIN asset_types t ON t.name = m.asset_type
+			JOIN asset_specs sp ON sp.id = a.specs_id
+			LEFT JOIN asset_status s ON s.asset_id = a.id AND s.archived_at IS NULL`
+
+// ListAssets returns a page of assets. Walking a cursor backward the rows still come in listing order.
 func ListAssets(params *models.ListAssetsQueryParams) ([]models.ListAssetsResponse, error) {
+	specsColumn := "NULL::JSONB"
+	if params.IncludeSpecs {
+		specsColumn = effectiveSpecsSQL
+	}
+
+	filters, args, argIndex := assetFilterSQL(params, 1)
+	listing, listArgs, _ := buildListingSQL(&params.ListParams, defaultAssetSort, "a.id", argIndex)
+	args = append(args, listArgs...)
+
 	// Base query
-	query := `
+	query := locationTreeCTE + `
 			SELECT 
 				a.id, a.serial_no, a.owned_by, a.purchased_date, 
 				m.name AS model_name, m.asset_type, 
 				b.name AS brand_name,
-				s.status
-			FROM assets a
-			JOIN asset_models m ON a.model_id = m.id
-			JOIN asset_brands b ON m.brand_id = b.id
-			LEFT JOIN asset_status s ON s.asset_id = a.id AND s.archived_at IS NULL
+				s.status, COALESCE(s.status = 'assigned' AND s.acknowledged_at IS NULL, FALSE),
+				s.location_id, lt.path, a.archived_at, ` + specsColumn + `, ` + listing.cursor + `,` + depreciationColumns +
+		assetListJoins + `
+			LEFT JOIN location_tree lt ON lt.id = s.location_id
 			WHERE 1=1
-		`
+		` + filters + listing.cond + listing.order
+
+	// Execute query
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		log.Printf("ListAssets query error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Parse results
+	var assets []models.ListAssetsResponse
+	for rows.Next() {
+		var item models.ListAssetsResponse
+		var specs []byte
+		dest := []any{&item.ID, &item.SerialNo, &item.OwnedBy, &item.PurchasedDate, &item.ModelName, &item.AssetType, &item.BrandName, &item.Status,
+			&item.PendingAcknowledgement, &item.LocationID, &item.Location, &item.ArchivedAt, &specs, pq.Array(&item.Cursor)}
+		err := rows.Scan(append(dest, scanCost(&item.Cost)...)...)
+		if err != nil {
+			log.Printf("Row scan error: %v", err)
+			continue
+		}
+		if specs != nil {
+			if err := json.Unmarshal(specs, &item.Specs); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal specs of asset %s: %w", item.ID, err)
+			}
+		}
+		assets = append(assets, item)
+	}
+
+	if params.Backward {
+		slices.Reverse(assets)
+	}
+	return assets, nil
+}
+
+// AssetFacetFields are what the asset listing can count its assets by
+var AssetFacetFields = []models.FacetField{
+	{Name: "asset_type", Column: "m.asset_type"},
+	{Name: "status", Column: "s.status::TEXT"},
+	{Name: "owned_by", Column: "a.owned_by::TEXT"},
+	{Name: "brand", Column: "b.name"},
+}
+
+// AssetFacets counts the assets matching the listing filters per value of each facet
+func AssetFacets(params *models.ListAssetsQueryParams, facets []models.FacetField) (map[string][]models.FacetCount, error) {
+	filters, args, _ := assetFilterSQL(params, 1)
+	return facetCounts(facets, assetListJoins, "WHERE 1=1"+filters, "a.id", args)
+}
+
+// CountAssets counts the assets matching the listing filters
+func CountAssets(params *models.ListAssetsQueryParams) (int, error) {
+	filters, args, _ := assetFilterSQL(params, 1)
+
+	var total int
+	err := DB.QueryRow("SELECT COUNT(*)"+assetListJoins+" WHERE 1=1"+filters, args...).Scan(&total)
+	return total, err
+}
+
+// StreamAssets runs the asset listing query without paging and hands each row to fn,
+// so exports of the whole inventory don't have to be held in memory.
+func StreamAssets(params *models.ListAssetsQueryParams, fn func(row *models.AssetExportRow) error) error {
+	query := locationTreeCTE + `
+		SELECT
+			a.id, a.serial_no, a.owned_by, a.purchased_date,
+			a.warranty_start_date, a.warranty_exp_date,
+			b.name, m.name, m.asset_type,
+			s.status, u.name, u.email, s.created_at, lt.path,
+			a.purchase_cost, a.currency, a.salvage_value,
+			` + effectiveSpecsSQL + `
+		FROM assets a
+		JOIN asset_models m ON a.model_id = m.id
+		JOIN asset_brands b ON m.brand_id = b.id
+		JOIN asset_specs sp ON sp.id = a.specs_id
+		LEFT JOIN asset_status s ON s.asset_id = a.id AND s.archived_at IS NULL
+		LEFT JOIN users u ON u.id = s.assigned_to_user
+		LEFT JOIN location_tree lt ON lt.id = s.location_id
+		WHERE 1=1
+	`
+
+	filters, args, _ := assetFilterSQL(params, 1)
+	query += filters + " ORDER BY a.created_at DESC"
 
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.AssetExportRow
+		var specs []byte
+		err := rows.Scan(
+			&row.ID, &row.SerialNo, &row.OwnedBy, &row.PurchasedDate,
+			&row.WarrantyStartDate, &row.WarrantyExpDate,
+			&row.BrandName, &row.ModelName, &row.AssetType,
+			&row.Status, &row.AssignedUserName, &row.AssignedUserEmail, &row.StatusSince, &row.LocationPath,
+			&row.PurchaseCost, &row.Currency, &row.SalvageValue,
+			&specs,
+		)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(specs, &row.Specs); err != nil {
+			return fmt.Errorf("failed to unmarshal specs of asset %s: %w", row.ID, err)
+		}
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// assetFilterSQL builds the WHERE conditions shared by asset listing and export.
+// It expects assets a, asset_models m, asset_brands b, asset_specs sp and the active asset_status s in the query.
+func assetFilterSQL(params *models.ListAssetsQueryParams, argIndex int) (string, []any, int) {
+	var query string
 	var args []any
-	argIndex := 1
 
 	// Text search on brand/model/serial_no
 	if params.Search != "" {
@@ -281,36 +397,72 @@ func ListAssets(params *models.ListAssetsQueryParams) ([]models.ListAssetsRespon
 	// Filter owned_by
 	if len(params.OwnedBy) > 0 {
 		query += fmt.Sprintf(" AND a.owned_by = ANY($%d)", argIndex)
-		args = append(args, params.OwnedBy)
+		args = append(args, pq.Array(params.OwnedBy))
 		argIndex++
 	}
 
-	query += ` ORDER BY a.created_at DESC
-	LIMIT $` + fmt.Sprint(argIndex) + ` OFFSET $` + fmt.Sprint(argIndex+1)
+	if len(params.IDs) > 0 {
+		query += fmt.Sprintf(" AND a.id::TEXT = ANY($%d)", argIndex)
+		args = append(args, pq.Array(params.IDs))
+		argIndex++
+	}
 
-	args = append(args, params.Limit, params.Offset)
+	// Filter location, including everything below it
+	if len(params.Locations) > 0 {
+		query += fmt.Sprintf(` AND s.location_id IN (
+				WITH RECURSIVE below AS (
+					SELECT id FROM locations WHERE id::TEXT = ANY($%d)
+					UNION
+					SELECT l.id FROM locations l JOIN below ON l.parent_id = below.id
+				)
+				SELECT id FROM below)`, argIndex)
+		args = append(args, pq.Array(params.Locations))
+		argIndex++
+	}
 
-	// Execute query
-	rows, err := DB.Query(query, args...)
-	if err != nil {
-		log.Printf("ListAssets query error: %v", err)
-		return nil, err
+	// Filter specs, the model defaults count like the values of the asset itself
+	for _, f := range params.Specs {
+		query += fmt.Sprintf(" AND m.asset_type = ANY($%d)", argIndex)
+		spec := fmt.Sprintf("(%s->>$%d)", effectiveSpecsSQL, argIndex+1)
+		if f.Numeric {
+			// CASE keeps the cast away from assets of other types
+			spec = fmt.Sprintf("(CASE WHEN m.asset_type = ANY($%d) THEN %s::NUMERIC END)", argIndex, spec)
+		}
+		args = append(args, pq.Array(f.AssetTypes), f.Field)
+		argIndex += 2
+
+		switch f.Op {
+		case "between":
+			query += fmt.Sprintf(" AND %s BETWEEN $%d AND $%d", spec, argIndex, argIndex+1)
+			args = append(args, f.Values[0], f.Values[1])
+			argIndex += 2
+		case "=":
+			query += fmt.Sprintf(" AND %s = ANY($%d)", spec, argIndex)
+			args = append(args, pq.Array(f.Values))
+			argIndex++
+		case "!=":
+			query += fmt.Sprintf(" AND %s <> ALL($%d)", spec, argIndex)
+			args = append(args, pq.Array(f.Values))
+			argIndex++
+		default:
+			query += fmt.Sprintf(" AND %s %s $%d", spec, f.Op, argIndex)
+			args = append(args, f.Values[0])
+			argIndex++
+		}
+	}
+
+	if params.SerialNo != "" {
+		query += fmt.Sprintf(" AND LOWER(a.serial_no) = LOWER($%d)", argIndex)
+		args = append(args, params.SerialNo)
+		argIndex++
 	}
-	defer rows.Close()
 
-	// Parse results
-	var assets []models.ListAssetsResponse
-	for rows.Next() {
-		var item models.ListAssetsResponse
-		err := rows.Scan(&item.ID, &item.SerialNo, &item.OwnedBy, &item.PurchasedDate, &item.ModelName, &item.AssetType, &item.BrandName, &item.Status)
-		if err != nil {
-			log.Printf("Row scan error: %v", err)
-			continue
-		}
-		assets = append(assets, item)
+	// Disposed assets are archived and hidden unless asked for
+	if !params.IncludeDisposed {
+		query += " AND a.archived_at IS NULL"
 	}
 
-	return assets, nil
+	return query, args, argIndex
 }
 
 func GetAssetWithModel(assetID string) (*models.AssetWithModel, error) {
@@ -324,7 +476,9 @@ func GetAssetWithModel(assetID string) (*models.AssetWithModel, error) {
 			a.purchased_date,
 			a.warranty_start_date,
 			a.warranty_exp_date,
-			m.asset_type
+			m.asset_type,
+			a.purchase_cost,
+			a.salvage_value
 		FROM assets a
 		JOIN asset_models m ON a.model_id = m.id
 		WHERE a.id = $1 AND a.archived_at IS NULL
@@ -341,6 +495,8 @@ func GetAssetWithModel(assetID string) (*models.AssetWithModel, error) {
 		&asset.WarrantyStartDate,
 		&asset.WarrantyExpDate,
 		&asset.AssetType,
+		&asset.PurchaseCost,
+		&asset.SalvageValue,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -352,6 +508,60 @@ func GetAssetWithModel(assetID string) (*models.AssetWithModel, error) {
 	return &asset, nil
 }
 
+func GetAssetDetail(assetID string) (*models.AssetDetail, error) {
+	query := locationTreeCTE + `
+		SELECT
+			a.id, a.serial_no, a.owned_by, a.purchased_date,
+			a.warranty_start_date, a.warranty_exp_date,
+			a.specs_id, a.created_at, a.archived_at,
+			b.id, b.name,
+			m.id, m.name, m.asset_type,
+			s.id, s.status, s.assigned_to_user, u.name, u.email,
+			s.sent_to_service, sv.name, s.remarks, s.location_id, lt.path, s.created_at, s.acknowledged_at,` + depreciationColumns + `
+		FROM assets a
+		JOIN asset_models m ON a.model_id = m.id
+		JOIN asset_brands b ON m.brand_id = b.id
+		JOIN asset_types t ON t.name = m.asset_type
+		LEFT JOIN asset_status s ON s.asset_id = a.id AND s.archived_at IS NULL
+		LEFT JOIN users u ON u.id = s.assigned_to_user
+		LEFT JOIN services sv ON sv.id = s.sent_to_service
+		LEFT JOIN location_tree lt ON lt.id = s.location_id
+		WHERE a.id = $1
+	`
+
+	var asset models.AssetDetail
+	var statusID, status sql.NullString
+	var statusSince sql.NullTime
+	current := models.AssetCurrentStatus{}
+	dest := []any{
+		&asset.ID, &asset.SerialNo, &asset.OwnedBy, &asset.PurchasedDate,
+		&asset.WarrantyStartDate, &asset.WarrantyExpDate,
+		&asset.SpecsID, &asset.CreatedAt, &asset.ArchivedAt,
+		&asset.Brand.ID, &asset.Brand.Name,
+		&asset.Model.ID, &asset.Model.Name, &asset.Model.AssetType,
+		&statusID, &status, &current.AssignedToUser, &current.AssignedUserName, &current.AssignedUserEmail,
+		&current.SentToService, &current.ServiceName, &current.Remarks, &current.LocationID, &current.Location, &statusSince,
+		&current.AcknowledgedAt,
+	}
+	err := DB.QueryRow(query, assetID).Scan(append(dest, scanCost(&asset.Cost)...)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if statusID.Valid {
+		current.StatusID = statusID.String
+		current.Status = status.String
+		current.Since = statusSince.Time
+		current.PendingAcknowledgement = current.Status == "assigned" && current.AcknowledgedAt == nil
+		asset.CurrentStatus = &current
+	}
+
+	return &asset, nil
+}
+
 func UpdateAsset(tx *sql.Tx, req *models.UpdateAssetRequest, assetID string, userID string) error {
 	// Dynamically build asset update query
 	setClauses := []string{}
@@ -388,6 +598,42 @@ func UpdateAsset(tx *sql.Tx, req *models.UpdateAssetRequest, assetID string, use
 		argID++
 	}
 
+	if req.PurchaseCost != nil {
+		setClauses = append(setClauses, fmt.Sprintf("purchase_cost = $%d", argID))
+		args = append(args, *req.PurchaseCost)
+		argID++
+	}
+
+	if req.Currency != nil {
+		setClauses = append(setClauses, fmt.Sprintf("currency = $%d", argID))
+		args = append(args, *req.Currency)
+		argID++
+	}
+
+	if req.SalvageValue != nil {
+		setClauses = append(setClauses, fmt.Sprintf("salvage_value = $%d", argID))
+		args = append(args, *req.SalvageValue)
+		argID++
+	}
+
+	if req.Method != nil {
+		setClauses = append(setClauses, fmt.Sprintf("depreciation_method = $%d", argID))
+		args = append(args, *req.Method)
+		argID++
+	}
+
+	if req.UsefulLifeMonths != nil {
+		setClauses = append(setClauses, fmt.Sprintf("useful_life_months = $%d", argID))
+		args = append(args, *req.UsefulLifeMonths)
+		argID++
+	}
+
+	if req.DecliningRate != nil {
+		setClauses = append(setClauses, fmt.Sprintf("declining_rate = $%d", argID))
+		args = append(args, *req.DecliningRate)
+		argID++
+	}
+
 	// Add updated_by and updated_at
 	setClauses = append(setClauses,
 		fmt.Sprintf("updated_by = $%d", argID),
@@ -411,100 +657,67 @@ func UpdateAsset(tx *sql.Tx, req *models.UpdateAssetRequest, assetID string, use
 	return nil
 }
 
-func UpdateSpecsByType(tx *sql.Tx, assetType string, specsID string, specs interface{}) error {
-	// Re-marshal into JSON
-	specsBytes, err := json.Marshal(specs)
+// UpdateSpecsByType merges the provided spec values into the specs of the asset.
+// A null value drops the override so the asset inherits the model default again.
+func UpdateSpecsByType(tx *sql.Tx, asset *models.AssetWithModel, specs interface{}) error {
+	def, err := GetAssetType(asset.AssetType)
 	if err != nil {
-		return fmt.Errorf("failed to re-marshal specs: %w", err)
+		return err
 	}
-	switch assetType {
-	case "laptop":
-		var s models.LaptopSpecsUpdate
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return fmt.Errorf("failed to unmarshal laptop specs: %w", err)
-		}
-		return UpdateLaptopSpecsByID(tx, specsID, &s)
 
-	case "mouse":
-		var s models.MouseSpecsUpdate
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return fmt.Errorf("failed to unmarshal specs: %w", err)
-		}
-		return UpdateMouseSpecsByID(tx, specsID, &s)
+	raw, err := utils.SpecsToMap(specs)
+	if err != nil {
+		return err
+	}
 
-	case "monitor":
-		var s models.MonitorSpecsUpdate
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return fmt.Errorf("failed to unmarshal specs: %w", err)
-		}
-		return UpdateMonitorSpecsByID(tx, specsID, &s)
+	cleaned, err := utils.ValidateSpecs(def.Fields, raw, true)
+	if err != nil {
+		return err
+	}
 
-	case "mobile":
-		var s models.MobileSpecsUpdate
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return fmt.Errorf("failed to unmarshal specs: %w", err)
-		}
-		return UpdateMobileSpecsByID(tx, specsID, &s)
+	if len(raw) == 0 {
+		return nil // no updates
+	}
 
-	case "sim":
-		var s models.SIMSpecsUpdate
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return fmt.Errorf("failed to unmarshal specs: %w", err)
-		}
-		return UpdateSIMSpecsByID(tx, specsID, &s)
+	defaults, err := GetModelDefaultSpecs(tx, asset.ModelID)
+	if err != nil {
+		return err
+	}
 
-	case "hard_disk":
-		var s models.HardDiskSpecsUpdate
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return fmt.Errorf("failed to unmarshal specs: %w", err)
-		}
-		return UpdateHardDiskSpecsByID(tx, specsID, &s)
+	var specsBytes []byte
+	if err := tx.QueryRow(`SELECT specs FROM asset_specs WHERE id = $1 FOR UPDATE`, asset.SpecsID).Scan(&specsBytes); err != nil {
+		return err
+	}
+	var own map[string]interface{}
+	if err := json.Unmarshal(specsBytes, &own); err != nil {
+		return fmt.Errorf("failed to unmarshal specs: %w", err)
+	}
 
-	case "pen_drive":
-		var s models.PenDriveSpecsUpdate
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return fmt.Errorf("failed to unmarshal specs: %w", err)
+	for _, field := range def.Fields {
+		val, ok := raw[field.Name]
+		if !ok || val != nil {
+			continue
 		}
-		return UpdatePenDriveSpecsByID(tx, specsID, &s)
-
-	case "accessories":
-		var s models.AccessorySpecsUpdate
-		if err := json.Unmarshal(specsBytes, &s); err != nil {
-			return fmt.Errorf("failed to unmarshal specs: %w", err)
+		if _, inherited := defaults[field.Name]; field.Required && !inherited {
+			return fmt.Errorf("%w: %s is required", utils.ErrInvalidSpecs, field.Name)
 		}
-		return UpdateAccessorySpecsByID(tx, specsID, &s)
-
-	default:
-		return errors.New("unsupported asset type: " + assetType)
+		delete(own, field.Name)
 	}
-}
-
-func buildSpecsUpdateSQL(tx *sql.Tx, table string, id string, fields map[string]interface{}) error {
-	var sets []string
-	var args []interface{}
-	argPos := 1
-
-	for col, val := range fields {
-		if val != nil {
-			sets = append(sets, fmt.Sprintf("%s = COALESCE($%d, %s)", col, argPos, col))
-			args = append(args, val)
-			argPos++
-		}
+	for key, val := range cleaned {
+		own[key] = val
 	}
 
-	if len(sets) == 0 {
-		return nil // no updates
+	specsBytes, err = json.Marshal(utils.SpecOverrides(defaults, own))
+	if err != nil {
+		return fmt.Errorf("failed to marshal specs: %w", err)
 	}
 
-	args = append(args, id)
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", table, strings.Join(sets, ", "), argPos)
-	log.Println(sets, args)
-	_, err := tx.Exec(query, args...)
+	_, err = tx.Exec(`UPDATE asset_specs SET specs = $1 WHERE id = $2`, specsBytes, asset.SpecsID)
 	return err
 }
 
 func IsAssetAvailable(tx *sql.Tx, assetID string) (bool, error) {
-	query := `SELECT COUNT(*) FROM asset_status WHERE asset_id = $1 AND status = 'assigned' AND archived_at IS NULL`
+	query := `SELECT COUNT(*) FROM asset_status WHERE asset_id = $1 AND status <> 'available' AND archived_at IS NULL`
 	var count int
 	err := tx.QueryRow(query, assetID).Scan(&count)
 	if err != nil {
@@ -514,11 +727,70 @@ func IsAssetAvailable(tx *sql.Tx, assetID string) (bool, error) {
 }
 
 func InsertAssetStatusToUser(tx *sql.Tx, status *models.AssignAssetRequest) error {
-	query := `INSERT INTO asset_status (asset_id, status, assigned_to_user) VALUES ($1, $2, $3)`
-	_, err := tx.Exec(query, status.AssetID, "assigned", status.UserID)
+	query := `
+		INSERT INTO asset_status (asset_id, status, assigned_to_user, location_id)
+		VALUES ($1, $2, $3, COALESCE($4, ` + remoteLocationSQL + `))
+	`
+	_, err := tx.Exec(query, status.AssetID, "assigned", status.UserID, status.LocationID)
 	return err
 }
 
+// InsertAssetStatusTransfer assigns the asset to the new holder, keeping the previous holder and the reason
+func InsertAssetStatusTransfer(tx *sql.Tx, req *models.TransferAssetRequest, fromUserID string) error {
+	query := `
+		INSERT INTO asset_status (asset_id, status, assigned_to_user, transferred_from, transfer_reason, location_id)
+		VALUES ($1, 'assigned', $2, $3, $4, COALESCE($5, ` + remoteLocationSQL + `))
+	`
+	_, err := tx.Exec(query, req.AssetID, req.ToUserID, fromUserID, req.Reason, req.LocationID)
+	return err
+}
+
+// AcknowledgeHandover records the employee's receipt of an asset assigned to them, false when nothing was pending
+func AcknowledgeHandover(assetID string, userID string, signature string) (bool, error) {
+	res, err := DB.Exec(`
+		UPDATE asset_status SET acknowledged_at = NOW(), acknowledgement_signature = $3
+		WHERE asset_id = $1 AND assigned_to_user = $2 AND status = 'assigned'
+		  AND archived_at IS NULL AND acknowledged_at IS NULL
+	`, assetID, userID, signature)
+	if err != nil {
+		return false, err
+	}
+	updated, err := res.RowsAffected()
+	return updated > 0, err
+}
+
+// ListPendingHandovers returns assignments not yet acknowledged for at least minDays days, oldest first
+func ListPendingHandovers(minDays int) ([]models.PendingHandover, error) {
+	rows, err := DB.Query(`
+		SELECT a.id, a.serial_no, ab.name, am.name, u.id, u.name, u.email, ast.created_at,
+			EXTRACT(DAY FROM NOW() - ast.created_at)::INT
+		FROM asset_status ast
+		JOIN assets a ON a.id = ast.asset_id
+		JOIN asset_models am ON am.id = a.model_id
+		JOIN asset_brands ab ON ab.id = am.brand_id
+		JOIN users u ON u.id = ast.assigned_to_user
+		WHERE ast.status = 'assigned' AND ast.archived_at IS NULL AND ast.acknowledged_at IS NULL
+		  AND ast.created_at <= NOW() - make_interval(days => $1)
+		ORDER BY ast.created_at
+	`, minDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var handovers []models.PendingHandover
+	for rows.Next() {
+		var h models.PendingHandover
+		err := rows.Scan(&h.AssetID, &h.SerialNo, &h.BrandName, &h.ModelName,
+			&h.UserID, &h.UserName, &h.UserEmail, &h.AssignedAt, &h.DaysOpen)
+		if err != nil {
+			return nil, err
+		}
+		handovers = append(handovers, h)
+	}
+	return handovers, rows.Err()
+}
+
 func GetActiveAssignedStatusID(tx *sql.Tx, assetID string) (string, error) {
 	query := `SELECT id FROM asset_status WHERE asset_id = $1 AND status = 'assigned' AND archived_at IS NULL`
 	var id string
@@ -537,12 +809,69 @@ func ArchiveAssetStatus(tx *sql.Tx, statusID string) error {
 	return err
 }
 
-func FetchAssetTimeline(assetID string) ([]models.AssetTimeline, error) {
+func GetActiveAssetStatus(tx *sql.Tx, assetID string) (*models.AssetStatus, error) {
 	query := `
-		SELECT status, assigned_to_user, sent_to_service, created_at, archived_at
+		SELECT id, asset_id, status, assigned_to_user, sent_to_service, created_at
 		FROM asset_status
-		WHERE asset_id = $1
-		ORDER BY created_at ASC
+		WHERE asset_id = $1 AND archived_at IS NULL
+		FOR UPDATE
+	`
+
+	var status models.AssetStatus
+	err := tx.QueryRow(query, assetID).Scan(
+		&status.ID,
+		&status.AssetID,
+		&status.Status,
+		&status.AssignedToUser,
+		&status.SentToService,
+		&status.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &status, nil
+}
+
+func InsertAssetStatusWithRemarks(tx *sql.Tx, assetID string, status string, remarks *string) error {
+	query := `
+		INSERT INTO asset_status (asset_id, status, remarks, location_id)
+		VALUES ($1, $2, $3, ` + lastStockedLocationSQL + `)
+	`
+	_, err := tx.Exec(query, assetID, status, remarks)
+	if err != nil {
+		return fmt.Errorf("failed to insert asset status: %w", err)
+	}
+	return nil
+}
+
+func InsertAssetStatusToService(tx *sql.Tx, req *models.SendToServiceRequest) error {
+	query := `
+		INSERT INTO asset_status (asset_id, status, sent_to_service, remarks, location_id)
+		VALUES ($1, $2, $3, $4, ` + lastStockedLocationSQL + `)
+	`
+	_, err := tx.Exec(query, req.AssetID, "service", req.ServiceID, req.Remarks)
+	return err
+}
+
+// CloseServiceStatus archives the active service row and records how the repair went.
+func CloseServiceStatus(tx *sql.Tx, statusID string, outcome string) error {
+	query := `UPDATE asset_status SET archived_at = NOW(), repair_outcome = $2 WHERE id = $1`
+	_, err := tx.Exec(query, statusID, outcome)
+	return err
+}
+
+func FetchAssetTimeline(assetID string) ([]models.AssetTimeline, error) {
+	query := locationTreeCTE + `
+		SELECT s.status, s.assigned_to_user, s.sent_to_service, s.remarks, s.repair_outcome,
+			s.transferred_from, s.transfer_reason, s.acknowledged_at, s.acknowledgement_signature,
+			s.location_id, lt.path, s.created_at, s.archived_at
+		FROM asset_status s
+		LEFT JOIN location_tree lt ON lt.id = s.location_id
+		WHERE s.asset_id = $1
+		ORDER BY s.created_at ASC
 	`
 
 	rows, err := DB.Query(query, assetID)
@@ -557,7 +886,10 @@ func FetchAssetTimeline(assetID string) ([]models.AssetTimeline, error) {
 		var assignedTo sql.NullString
 		var sentToService sql.NullString
 		var archivedAt sql.NullTime
-		if err := rows.Scan(&t.Status, &assignedTo, &sentToService, &t.CreatedAt, &archivedAt); err != nil {
+		err := rows.Scan(&t.Status, &assignedTo, &sentToService, &t.Remarks, &t.RepairOutcome,
+			&t.TransferredFrom, &t.TransferReason, &t.AcknowledgedAt, &t.Signature, &t.LocationID, &t.Location,
+			&t.CreatedAt, &archivedAt)
+		if err != nil {
 			return nil, err
 		}
 		if assignedTo.Valid {
@@ -576,11 +908,13 @@ func FetchAssetTimeline(assetID string) ([]models.AssetTimeline, error) {
 
 func FetchUserAssetTimeline(userID string) ([]models.AssetTimeline, error) {
 	//sent_to_service redundant remove later
-	query := `
-		SELECT status, asset_id, sent_to_service, created_at, archived_at
-		FROM asset_status
-		WHERE assigned_to_user = $1
-		ORDER BY created_at ASC
+	query := locationTreeCTE + `
+		SELECT s.status, s.asset_id, s.sent_to_service, s.transferred_from, s.transfer_reason,
+			s.acknowledged_at, s.acknowledgement_signature, s.location_id, lt.path, s.created_at, s.archived_at
+		FROM asset_status s
+		LEFT JOIN location_tree lt ON lt.id = s.location_id
+		WHERE s.assigned_to_user = $1
+		ORDER BY s.created_at ASC
 	`
 
 	rows, err := DB.Query(query, userID)
@@ -595,7 +929,8 @@ func FetchUserAssetTimeline(userID string) ([]models.AssetTimeline, error) {
 		var assetID string
 		var sentToService sql.NullString
 		var archivedAt sql.NullTime
-		if err := rows.Scan(&t.Status, &assetID, &sentToService, &t.CreatedAt, &archivedAt); err != nil {
+		if err := rows.Scan(&t.Status, &assetID, &sentToService, &t.TransferredFrom, &t.TransferReason,
+			&t.AcknowledgedAt, &t.Signature, &t.LocationID, &t.Location, &t.CreatedAt, &archivedAt); err != nil {
 			return nil, err
 		}
 		t.AssignedToUser = &userID
@@ -626,7 +961,7 @@ func NumberOfAssetsAssigned(tx *sql.Tx, userID string) (int, error) {
 func GetAllAssetsByUser(userID string, user *models.UserDetails) error {
 	// Fetch detailed assigned assets
 	assetQuery := `
-		SELECT a.id, am.name, ab.name, ast.status, ast.created_at
+		SELECT a.id, am.name, ab.name, ast.status, ast.created_at, ast.acknowledged_at
 		FROM asset_status ast
 		JOIN assets a ON a.id = ast.asset_id
 		JOIN asset_models am ON am.id = a.model_id
@@ -642,7 +977,7 @@ func GetAllAssetsByUser(userID string, user *models.UserDetails) error {
 
 	for rows.Next() {
 		var asset models.AssignedAsset
-		err := rows.Scan(&asset.AssetID, &asset.ModelName, &asset.BrandName, &asset.Status, &asset.AssignedAt)
+		err := rows.Scan(&asset.AssetID, &asset.ModelName, &asset.BrandName, &asset.Status, &asset.AssignedAt, &asset.AcknowledgedAt)
 		if err != nil {
 			return err
 		}
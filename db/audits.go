@@ -0,0 +1,263 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"storex/models"
+)
+
+func CreateAudit(tx *sql.Tx, req *models.CreateAuditRequest, authUserID string) (string, error) {
+	var auditID string
+	err := tx.QueryRow(`
+		INSERT INTO asset_audits (name, scope_asset_types, scope_owned_by, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, req.Name, pq.Array(req.AssetTypes), pq.Array(req.OwnedBy), authUserID).Scan(&auditID)
+	if err != nil {
+		return "", err
+	}
+	return auditID, nil
+}
+
+// SnapshotAuditScope records every active asset in scope with its current status and holder
+func SnapshotAuditScope(tx *sql.Tx, auditID string, req *models.CreateAuditRequest) (int64, error) {
+	res, err := tx.Exec(`
+		INSERT INTO asset_audit_expected (audit_id, asset_id, status, assigned_to_user)
+		SELECT $1, a.id, s.status, s.assigned_to_user
+		FROM assets a
+		JOIN asset_models m ON m.id = a.model_id
+		LEFT JOIN asset_status s ON s.asset_id = a.id AND s.archived_at IS NULL
+		WHERE a.archived_at IS NULL
+		  AND (cardinality($2::TEXT[]) = 0 OR m.asset_type = ANY($2))
+		  AND (cardinality($3::TEXT[]) = 0 OR a.owned_by::TEXT = ANY($3))
+	`, auditID, pq.Array(req.AssetTypes), pq.Array(req.OwnedBy))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// LockAudit fetches an audit for a change, nil when it does not exist
+func LockAudit(tx *sql.Tx, auditID string) (*models.Audit, error) {
+	var audit models.Audit
+	err := tx.QueryRow(`
+		SELECT id, name, status, created_at
+		FROM asset_audits
+		WHERE id = $1
+		FOR UPDATE
+	`, auditID).Scan(&audit.ID, &audit.Name, &audit.Status, &audit.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// RecordAuditScan stores a sighting, scanning the same asset or unknown code again replaces the earlier one
+func RecordAuditScan(tx *sql.Tx, auditID string, assetID *string, req *models.AuditScanRequest, current *models.AssetStatus, authUserID string) error {
+	var recordedStatus, recordedUser *string
+	if current != nil {
+		recordedStatus = &current.Status
+		recordedUser = current.AssignedToUser
+	}
+
+	conflict := "(audit_id, asset_id) WHERE asset_id IS NOT NULL"
+	if assetID == nil {
+		conflict = "(audit_id, LOWER(code)) WHERE asset_id IS NULL"
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO asset_audit_scans (
+			audit_id, asset_id, code, location, condition, seen_with_user,
+			recorded_status, recorded_user, remarks, scanned_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT `+conflict+` DO UPDATE SET
+			code = EXCLUDED.code,
+			location = EXCLUDED.location,
+			condition = EXCLUDED.condition,
+			seen_with_user = EXCLUDED.seen_with_user,
+			recorded_status = EXCLUDED.recorded_status,
+			recorded_user = EXCLUDED.recorded_user,
+			remarks = EXCLUDED.remarks,
+			scanned_by = EXCLUDED.scanned_by,
+			scanned_at = NOW()
+	`, auditID, assetID, req.Code, req.Location, req.Condition, req.SeenWithUser,
+		recordedStatus, recordedUser, req.Remarks, authUserID)
+	return err
+}
+
+func CloseAudit(tx *sql.Tx, auditID string, authUserID string) error {
+	_, err := tx.Exec(`
+		UPDATE asset_audits SET status = 'closed', closed_at = NOW(), closed_by = $2
+		WHERE id = $1
+	`, auditID, authUserID)
+	return err
+}
+
+const auditColumns = `
+	au.id, au.name, au.scope_asset_types, au.scope_owned_by, au.status,
+	(SELECT COUNT(*) FROM asset_audit_expected e WHERE e.audit_id = au.id),
+	(SELECT COUNT(*) FROM asset_audit_scans sc WHERE sc.audit_id = au.id),
+	au.created_at, au.closed_at
+	FROM asset_audits au`
+
+func scanAudit(row interface{ Scan(...any) error }, audit *models.Audit) error {
+	return row.Scan(
+		&audit.ID, &audit.Name, pq.Array(&audit.AssetTypes), pq.Array(&audit.OwnedBy), &audit.Status,
+		&audit.Expected, &audit.Scanned, &audit.CreatedAt, &audit.ClosedAt,
+	)
+}
+
+// ListAudits returns audits newest first, optionally only those in the given statuses
+func ListAudits(status []string) ([]models.Audit, error) {
+	query := "SELECT" + auditColumns + " WHERE 1=1"
+	var args []any
+	if len(status) > 0 {
+		query += " AND au.status::TEXT = ANY($1)"
+		args = append(args, pq.Array(status))
+	}
+	query += " ORDER BY au.created_at DESC"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var audits []models.Audit
+	for rows.Next() {
+		var audit models.Audit
+		if err := scanAudit(rows, &audit); err != nil {
+			return nil, err
+		}
+		audits = append(audits, audit)
+	}
+	return audits, rows.Err()
+}
+
+// GetAudit returns an audit with its progress, nil when it does not exist
+func GetAudit(auditID string) (*models.Audit, error) {
+	var audit models.Audit
+	err := scanAudit(DB.QueryRow("SELECT"+auditColumns+" WHERE au.id = $1", auditID), &audit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &audit, nil
+}
+
+const auditScanColumns = `
+	sc.audit_id, au.name, sc.asset_id, a.serial_no, b.name, m.name, sc.code, sc.location, sc.condition,
+	sc.seen_with_user, sc.recorded_status, sc.recorded_user, sc.remarks, sc.scanned_at,
+	e.asset_id IS NOT NULL
+	FROM asset_audit_scans sc
+	JOIN asset_audits au ON au.id = sc.audit_id
+	LEFT JOIN assets a ON a.id = sc.asset_id
+	LEFT JOIN asset_models m ON m.id = a.model_id
+	LEFT JOIN asset_brands b ON b.id = m.brand_id
+	LEFT JOIN asset_audit_expected e ON e.audit_id = sc.audit_id AND e.asset_id = sc.asset_id`
+
+func queryAuditScans(query string, arg string) ([]models.AuditScan, []bool, error) {
+	rows, err := DB.Query(query, arg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var scans []models.AuditScan
+	var inScope []bool
+	for rows.Next() {
+		var sc models.AuditScan
+		var expected bool
+		err := rows.Scan(
+			&sc.AuditID, &sc.AuditName, &sc.AssetID, &sc.SerialNo, &sc.BrandName, &sc.ModelName,
+			&sc.Code, &sc.Location, &sc.Condition, &sc.SeenWithUser, &sc.RecordedStatus,
+			&sc.RecordedUser, &sc.Remarks, &sc.ScannedAt, &expected,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		scans = append(scans, sc)
+		inScope = append(inScope, expected)
+	}
+	return scans, inScope, rows.Err()
+}
+
+// GetAuditReport sorts the scans of an audit into found, unexpected and holder mismatches
+// and lists the expected assets nobody saw, nil when the audit does not exist
+func GetAuditReport(auditID string) (*models.AuditReport, error) {
+	audit, err := GetAudit(auditID)
+	if err != nil || audit == nil {
+		return nil, err
+	}
+
+	report := models.AuditReport{
+		Audit:            *audit,
+		Found:            []models.AuditScan{},
+		Missing:          []models.AuditExpectedAsset{},
+		Unexpected:       []models.AuditScan{},
+		HolderMismatches: []models.AuditScan{},
+	}
+
+	scans, inScope, err := queryAuditScans("SELECT"+auditScanColumns+" WHERE sc.audit_id = $1 ORDER BY sc.scanned_at", auditID)
+	if err != nil {
+		return nil, err
+	}
+	for i, sc := range scans {
+		if inScope[i] {
+			report.Found = append(report.Found, sc)
+		} else {
+			report.Unexpected = append(report.Unexpected, sc)
+		}
+
+		// an asset found in storage while assigned is as much a mismatch as one found with the wrong person
+		if sc.AssetID != nil && !sameUser(sc.SeenWithUser, sc.RecordedUser) {
+			report.HolderMismatches = append(report.HolderMismatches, sc)
+		}
+	}
+
+	rows, err := DB.Query(`
+		SELECT e.asset_id, a.serial_no, b.name, m.name, e.status, e.assigned_to_user
+		FROM asset_audit_expected e
+		JOIN assets a ON a.id = e.asset_id
+		JOIN asset_models m ON m.id = a.model_id
+		JOIN asset_brands b ON b.id = m.brand_id
+		WHERE e.audit_id = $1
+		  AND NOT EXISTS (SELECT 1 FROM asset_audit_scans sc WHERE sc.audit_id = e.audit_id AND sc.asset_id = e.asset_id)
+		ORDER BY b.name, m.name, a.serial_no
+	`, auditID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var missing models.AuditExpectedAsset
+		err := rows.Scan(&missing.AssetID, &missing.SerialNo, &missing.BrandName, &missing.ModelName,
+			&missing.Status, &missing.AssignedToUser)
+		if err != nil {
+			return nil, err
+		}
+		report.Missing = append(report.Missing, missing)
+	}
+	return &report, rows.Err()
+}
+
+func sameUser(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// ListAssetAuditHistory returns every audit sighting of the asset, newest first
+func ListAssetAuditHistory(assetID string) ([]models.AuditScan, error) {
+	scans, _, err := queryAuditScans("SELECT"+auditScanColumns+" WHERE sc.asset_id = $1 ORDER BY sc.scanned_at DESC", assetID)
+	return scans, err
+}
@@ -0,0 +1,521 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"reflect"
+	"storex/models"
+)
+
+var (
+	ErrUnknownBrand    = errors.New("unknown brand")
+	ErrUnknownModel    = errors.New("unknown model")
+	ErrCatalogConflict = errors.New("catalog conflict")
+)
+
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// findModelID looks a model up by brand and model name without creating anything
+func findModelID(q queryRower, req *models.CreateModelRequest) (string, error) {
+	var brandID string
+	err := q.QueryRow(`SELECT id FROM asset_brands WHERE LOWER(name) = LOWER($1)`, req.Brand.Name).Scan(&brandID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %s", ErrUnknownBrand, req.Brand.Name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var modelID, assetType string
+	err = q.QueryRow(`
+		SELECT id, asset_type FROM asset_models
+		WHERE LOWER(name) = LOWER($1) AND brand_id = $2
+	`, req.Name, brandID).Scan(&modelID, &assetType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %s %s", ErrUnknownModel, req.Brand.Name, req.Name)
+	}
+	if err != nil {
+		return "", err
+	}
+	if assetType != req.AssetType {
+		return "", fmt.Errorf("%w: %s %s is a %s", ErrUnknownModel, req.Brand.Name, req.Name, assetType)
+	}
+	return modelID, nil
+}
+
+// FindModelID checks that the brand and model of a request are in the catalog
+func FindModelID(req *models.CreateModelRequest) (string, error) {
+	return findModelID(DB, req)
+}
+
+// ResolveModel returns the model of an asset request. Unless strict, missing brands and models are created.
+func ResolveModel(tx *sql.Tx, req *models.CreateModelRequest, strict bool) (string, error) {
+	if strict {
+		return findModelID(tx, req)
+	}
+
+	brandID, err := GetOrCreateBrand(tx, req.Brand.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve brand: %w", err)
+	}
+	modelID, err := GetOrCreateModel(tx, brandID, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve model: %w", err)
+	}
+	return modelID, nil
+}
+
+const brandColumns = `
+	b.id, b.name,
+	(SELECT COUNT(*) FROM asset_models m WHERE m.brand_id = b.id),
+	(SELECT COUNT(*) FROM assets a JOIN asset_models m ON m.id = a.model_id
+		WHERE m.brand_id = b.id AND a.archived_at IS NULL),
+	b.created_at
+	FROM asset_brands b`
+
+func scanBrand(row interface{ Scan(...any) error }, brand *models.Brand) error {
+	return row.Scan(&brand.ID, &brand.Name, &brand.ModelCount, &brand.AssetCount, &brand.CreatedAt)
+}
+
+// ListBrands returns the brands by name, optionally only those whose name contains search
+func ListBrands(search string) ([]models.Brand, error) {
+	rows, err := DB.Query("SELECT"+brandColumns+`
+		WHERE $1 = '' OR b.name ILIKE '%' || $1 || '%'
+		ORDER BY LOWER(b.name)
+	`, search)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var brands []models.Brand
+	for rows.Next() {
+		var brand models.Brand
+		if err := scanBrand(rows, &brand); err != nil {
+			return nil, err
+		}
+		brands = append(brands, brand)
+	}
+	return brands, rows.Err()
+}
+
+// GetBrand returns a brand with its usage, nil when it does not exist
+func GetBrand(brandID string) (*models.Brand, error) {
+	var brand models.Brand
+	if err := scanBrand(DB.QueryRow("SELECT"+brandColumns+" WHERE b.id::TEXT = $1", brandID), &brand); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &brand, nil
+}
+
+// LockBrand fetches a brand for a change, nil when it does not exist
+func LockBrand(tx *sql.Tx, brandID string) (*models.Brand, error) {
+	var brand models.Brand
+	err := tx.QueryRow(`SELECT id, name FROM asset_brands WHERE id::TEXT = $1 FOR UPDATE`, brandID).
+		Scan(&brand.ID, &brand.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &brand, nil
+}
+
+// IsBrandNameTaken checks the name against the other brands, ignoring case
+func IsBrandNameTaken(tx *sql.Tx, name string, exceptBrandID string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM asset_brands WHERE LOWER(name) = LOWER($1) AND id::TEXT <> $2
+	`, name, exceptBrandID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func CreateBrand(tx *sql.Tx, name string, authUserID string) (string, error) {
+	var brandID string
+	err := tx.QueryRow(`
+		INSERT INTO asset_brands (name, created_by) VALUES ($1, $2) RETURNING id
+	`, name, authUserID).Scan(&brandID)
+	if err != nil {
+		return "", err
+	}
+	return brandID, nil
+}
+
+func RenameBrand(tx *sql.Tx, brandID string, name string, authUserID string) (int64, error) {
+	res, err := tx.Exec(`
+		UPDATE asset_brands SET name = $2, updated_at = NOW(), updated_by = $3 WHERE id::TEXT = $1
+	`, brandID, name, authUserID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteBrand removes a brand that has no models left
+func DeleteBrand(tx *sql.Tx, brandID string) (int64, error) {
+	res, err := tx.Exec(`
+		DELETE FROM asset_brands b
+		WHERE b.id::TEXT = $1 AND NOT EXISTS (SELECT 1 FROM asset_models m WHERE m.brand_id = b.id)
+	`, brandID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// MergeBrands moves every model of the source brand to the target and deletes the source.
+// A model the target already has under the same name absorbs the source model and its assets.
+func MergeBrands(tx *sql.Tx, sourceID string, targetID string, authUserID string) (*models.MergeResult, error) {
+	var clashes, absorbed, absorbing []string
+	rows, err := tx.Query(`
+		SELECT sm.id, tm.id, sm.asset_type <> tm.asset_type,
+			sm.name || ' (' || sm.asset_type || ' vs ' || tm.asset_type || ')'
+		FROM asset_models sm
+		JOIN asset_models tm ON LOWER(tm.name) = LOWER(sm.name) AND tm.brand_id = $2
+		WHERE sm.brand_id = $1
+	`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var sourceModelID, targetModelID, clash string
+		var typeDiffers bool
+		if err := rows.Scan(&sourceModelID, &targetModelID, &typeDiffers, &clash); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if typeDiffers {
+			clashes = append(clashes, clash)
+		}
+		absorbed = append(absorbed, sourceModelID)
+		absorbing = append(absorbing, targetModelID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(clashes) > 0 {
+		return nil, fmt.Errorf("%w: models of the same name have different asset types: %v", ErrCatalogConflict, clashes)
+	}
+
+	result := models.MergeResult{IntoID: targetID}
+
+	// assets of absorbed models keep their specs, whatever the defaults of the absorbing model are
+	for _, modelID := range absorbed {
+		if err := inlineDefaultSpecs(tx, modelID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := tx.Exec(`
+		UPDATE assets a SET model_id = tm.id, updated_at = NOW(), updated_by = $3
+		FROM asset_models sm
+		JOIN asset_models tm ON LOWER(tm.name) = LOWER(sm.name) AND tm.brand_id = $2
+		WHERE sm.brand_id = $1 AND a.model_id = sm.id
+	`, sourceID, targetID, authUserID)
+	if err != nil {
+		return nil, err
+	}
+	if result.RepointedAssets, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+	for _, modelID := range absorbing {
+		if err := foldDefaultSpecs(tx, modelID); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err = tx.Exec(`
+		DELETE FROM asset_models sm
+		USING asset_models tm
+		WHERE sm.brand_id = $1 AND tm.brand_id = $2 AND LOWER(tm.name) = LOWER(sm.name)
+	`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if result.MergedModels, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	res, err = tx.Exec(`
+		UPDATE asset_models SET brand_id = $2, updated_at = NOW(), updated_by = $3 WHERE brand_id = $1
+	`, sourceID, targetID, authUserID)
+	if err != nil {
+		return nil, err
+	}
+	if result.MovedModels, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM asset_brands WHERE id = $1`, sourceID); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+const modelColumns = `
+	m.id, m.name, m.brand_id, b.name, m.asset_type,
+	(SELECT COUNT(*) FROM assets a WHERE a.model_id = m.id AND a.archived_at IS NULL),
+	m.created_at, m.default_specs
+	FROM asset_models m
+	JOIN asset_brands b ON b.id = m.brand_id`
+
+func scanModel(row interface{ Scan(...any) error }, model *models.Model) error {
+	var defaults []byte
+	err := row.Scan(&model.ID, &model.Name, &model.BrandID, &model.BrandName, &model.AssetType,
+		&model.AssetCount, &model.CreatedAt, &defaults)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(defaults, &model.DefaultSpecs); err != nil {
+		return fmt.Errorf("corrupt default specs of model %s: %w", model.ID, err)
+	}
+	return nil
+}
+
+func ListModels(params *models.ListModelsQueryParams) ([]models.Model, error) {
+	query := "SELECT" + modelColumns + " WHERE 1=1"
+	var args []any
+	argIndex := 1
+
+	if params.Search != "" {
+		query += fmt.Sprintf(" AND (m.name ILIKE $%d OR b.name ILIKE $%d)", argIndex, argIndex)
+		args = append(args, "%"+params.Search+"%")
+		argIndex++
+	}
+	if len(params.BrandIDs) > 0 {
+		query += fmt.Sprintf(" AND m.brand_id::TEXT = ANY($%d)", argIndex)
+		args = append(args, pq.Array(params.BrandIDs))
+		argIndex++
+	}
+	if len(params.AssetTypes) > 0 {
+		query += fmt.Sprintf(" AND m.asset_type = ANY($%d)", argIndex)
+		args = append(args, pq.Array(params.AssetTypes))
+		argIndex++
+	}
+	query += " ORDER BY LOWER(b.name), LOWER(m.name)"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Model
+	for rows.Next() {
+		var model models.Model
+		if err := scanModel(rows, &model); err != nil {
+			return nil, err
+		}
+		list = append(list, model)
+	}
+	return list, rows.Err()
+}
+
+// GetModel returns a model with its usage, nil when it does not exist
+func GetModel(modelID string) (*models.Model, error) {
+	var model models.Model
+	if err := scanModel(DB.QueryRow("SELECT"+modelColumns+" WHERE m.id::TEXT = $1", modelID), &model); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &model, nil
+}
+
+// LockModel fetches a model for a change, nil when it does not exist
+func LockModel(tx *sql.Tx, modelID string) (*models.Model, error) {
+	var model models.Model
+	var defaults []byte
+	err := tx.QueryRow(`
+		SELECT m.id, m.name, m.brand_id, m.asset_type,
+			(SELECT COUNT(*) FROM assets a WHERE a.model_id = m.id),
+			m.default_specs
+		FROM asset_models m
+		WHERE m.id::TEXT = $1
+		FOR UPDATE
+	`, modelID).Scan(&model.ID, &model.Name, &model.BrandID, &model.AssetType, &model.AssetCount, &defaults)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(defaults, &model.DefaultSpecs); err != nil {
+		return nil, fmt.Errorf("corrupt default specs of model %s: %w", model.ID, err)
+	}
+	return &model, nil
+}
+
+// IsModelNameTaken checks the name against the other models of the brand, ignoring case
+func IsModelNameTaken(tx *sql.Tx, brandID string, name string, exceptModelID string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM asset_models
+		WHERE brand_id = $1 AND LOWER(name) = LOWER($2) AND id::TEXT <> $3
+	`, brandID, name, exceptModelID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func CreateModel(tx *sql.Tx, req *models.CatalogModelRequest, authUserID string) (string, error) {
+	defaults, err := json.Marshal(req.DefaultSpecs)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal default specs: %w", err)
+	}
+
+	var modelID string
+	err = tx.QueryRow(`
+		INSERT INTO asset_models (name, brand_id, asset_type, default_specs, created_by)
+		VALUES ($1, $2, $3, COALESCE($4::JSONB, '{}'), $5)
+		RETURNING id
+	`, req.Name, req.BrandID, req.AssetType, defaults, authUserID).Scan(&modelID)
+	if err != nil {
+		return "", err
+	}
+	return modelID, nil
+}
+
+func UpdateModel(tx *sql.Tx, modelID string, req *models.UpdateModelRequest, authUserID string) error {
+	_, err := tx.Exec(`
+		UPDATE asset_models SET
+			name = COALESCE($2, name),
+			brand_id = COALESCE($3, brand_id),
+			asset_type = COALESCE($4, asset_type),
+			updated_at = NOW(),
+			updated_by = $5
+		WHERE id = $1
+	`, modelID, req.Name, req.BrandID, req.AssetType, authUserID)
+	return err
+}
+
+// GetModelDefaultSpecs returns the specs every asset of the model inherits
+func GetModelDefaultSpecs(q queryRower, modelID string) (map[string]interface{}, error) {
+	var defaults []byte
+	if err := q.QueryRow(`SELECT default_specs FROM asset_models WHERE id = $1`, modelID).Scan(&defaults); err != nil {
+		return nil, err
+	}
+
+	var specs map[string]interface{}
+	if err := json.Unmarshal(defaults, &specs); err != nil {
+		return nil, fmt.Errorf("corrupt default specs of model %s: %w", modelID, err)
+	}
+	return specs, nil
+}
+
+// SetModelDefaultSpecs replaces the default specs of a model. New defaults only apply to assets
+// created from now on, existing assets keep the value of a default that is removed or changed.
+// Values of assets equal to the new defaults are folded into them.
+func SetModelDefaultSpecs(tx *sql.Tx, model *models.Model, defaults map[string]interface{}, authUserID string) error {
+	var changed []string
+	for key, old := range model.DefaultSpecs {
+		if val, ok := defaults[key]; !ok || !reflect.DeepEqual(old, val) {
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) > 0 {
+		if err := inlineDefaultSpecs(tx, model.ID, changed); err != nil {
+			return err
+		}
+	}
+
+	defaultsBytes, err := json.Marshal(defaults)
+	if err != nil {
+		return fmt.Errorf("failed to marshal default specs: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE asset_models SET default_specs = $2, updated_at = NOW(), updated_by = $3 WHERE id = $1
+	`, model.ID, defaultsBytes, authUserID)
+	if err != nil {
+		return err
+	}
+
+	return foldDefaultSpecs(tx, model.ID)
+}
+
+// inlineDefaultSpecs copies the defaults of a model into the own specs of its assets,
+// all of them unless keys are given, so the assets keep them when leaving the model
+func inlineDefaultSpecs(tx *sql.Tx, modelID string, keys []string) error {
+	_, err := tx.Exec(`
+		UPDATE asset_specs sp SET specs = (
+			SELECT COALESCE(jsonb_object_agg(d.key, d.value), '{}')
+			FROM jsonb_each(m.default_specs) d
+			WHERE $2::TEXT[] IS NULL OR d.key = ANY($2)
+		) || sp.specs
+		FROM assets a
+		JOIN asset_models m ON m.id = a.model_id
+		WHERE sp.id = a.specs_id AND a.model_id = $1
+	`, modelID, pq.Array(keys))
+	return err
+}
+
+// foldDefaultSpecs drops the own spec values of the assets of a model that equal its defaults
+func foldDefaultSpecs(tx *sql.Tx, modelID string) error {
+	_, err := tx.Exec(`
+		UPDATE asset_specs sp SET specs = COALESCE((
+			SELECT jsonb_object_agg(o.key, o.value)
+			FROM jsonb_each(sp.specs) o
+			WHERE m.default_specs->o.key IS DISTINCT FROM o.value
+		), '{}')
+		FROM assets a
+		JOIN asset_models m ON m.id = a.model_id
+		WHERE sp.id = a.specs_id AND a.model_id = $1
+	`, modelID)
+	return err
+}
+
+// DeleteModel removes a model no asset uses, disposed ones included
+func DeleteModel(tx *sql.Tx, modelID string) (int64, error) {
+	res, err := tx.Exec(`
+		DELETE FROM asset_models m
+		WHERE m.id::TEXT = $1 AND NOT EXISTS (SELECT 1 FROM assets a WHERE a.model_id = m.id)
+	`, modelID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// MergeModels repoints every asset of the source model to the target and deletes the source
+func MergeModels(tx *sql.Tx, sourceID string, targetID string, authUserID string) (*models.MergeResult, error) {
+	result := models.MergeResult{IntoID: targetID}
+
+	// the assets keep their specs, whatever the defaults of the target are
+	if err := inlineDefaultSpecs(tx, sourceID, nil); err != nil {
+		return nil, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE assets SET model_id = $2, updated_at = NOW(), updated_by = $3 WHERE model_id = $1
+	`, sourceID, targetID, authUserID)
+	if err != nil {
+		return nil, err
+	}
+	if result.RepointedAssets, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+	if err := foldDefaultSpecs(tx, targetID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM asset_models WHERE id = $1`, sourceID); err != nil {
+		return nil, err
+	}
+	result.MergedModels = 1
+	return &result, nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"storex/models"
+	"time"
+)
+
+// depreciationColumns selects the cost of asset a with its policy resolved against asset type t
+const depreciationColumns = `
+	a.purchase_cost, a.currency, a.salvage_value, a.purchased_date,
+	COALESCE(a.depreciation_method, t.depreciation_method),
+	COALESCE(a.useful_life_months, t.useful_life_months),
+	COALESCE(a.declining_rate, t.declining_rate)`
+
+// scanCost lists the scan destinations matching depreciationColumns
+func scanCost(cost *models.AssetCost) []any {
+	return []any{
+		&cost.PurchaseCost, &cost.Currency, &cost.SalvageValue, &cost.PurchasedDate,
+		&cost.Policy.Method, &cost.Policy.UsefulLifeMonths, &cost.Policy.DecliningRate,
+	}
+}
+
+// ListDepreciableAssets returns assets with a purchase cost that were held at some point in [from, to)
+func ListDepreciableAssets(from time.Time, to time.Time) ([]models.DepreciableAsset, error) {
+	query := `
+		SELECT
+			a.id, a.serial_no, b.name, m.name, m.asset_type, a.archived_at,` + depreciationColumns + `
+		FROM assets a
+		JOIN asset_models m ON a.model_id = m.id
+		JOIN asset_brands b ON m.brand_id = b.id
+		JOIN asset_types t ON t.name = m.asset_type
+		WHERE a.purchase_cost IS NOT NULL
+		  AND a.purchased_date < $2
+		  AND (a.archived_at IS NULL OR a.archived_at >= $1)
+		ORDER BY m.asset_type, a.purchased_date, a.serial_no
+	`
+
+	rows, err := DB.Query(query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assets []models.DepreciableAsset
+	for rows.Next() {
+		var asset models.DepreciableAsset
+		dest := []any{&asset.AssetID, &asset.SerialNo, &asset.BrandName, &asset.ModelName, &asset.AssetType, &asset.ArchivedAt}
+		if err := rows.Scan(append(dest, scanCost(&asset.Cost)...)...); err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	return assets, rows.Err()
+}
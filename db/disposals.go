@@ -0,0 +1,96 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"storex/models"
+)
+
+func InsertAssetDisposal(tx *sql.Tx, assetID string, req *models.DisposeAssetRequest, authUserID string) (string, error) {
+	query := `
+		INSERT INTO asset_disposals (
+			asset_id, reason, approved_by, sale_value, certificate_ref, remarks, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var disposalID string
+	err := tx.QueryRow(query,
+		assetID,
+		req.Reason,
+		req.ApprovedBy,
+		req.SaleValue,
+		req.CertificateRef,
+		req.Remarks,
+		authUserID,
+	).Scan(&disposalID)
+	if err != nil {
+		return "", err
+	}
+	return disposalID, nil
+}
+
+// LockAssetForDisposal locks the asset row so concurrent disposals queue up behind each other.
+// It tells whether the asset exists and whether it is already disposed.
+func LockAssetForDisposal(tx *sql.Tx, assetID string) (bool, bool, error) {
+	var disposed bool
+	err := tx.QueryRow(`
+		SELECT archived_at IS NOT NULL FROM assets WHERE id = $1 FOR UPDATE
+	`, assetID).Scan(&disposed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	return true, disposed, nil
+}
+
+func ArchiveAsset(tx *sql.Tx, assetID string, authUserID string) error {
+	_, err := tx.Exec(`
+		UPDATE assets SET archived_at = NOW(), archived_by = $2 WHERE id = $1 AND archived_at IS NULL
+	`, assetID, authUserID)
+	return err
+}
+
+func ListAssetDisposals(reasons []string) ([]models.AssetDisposal, error) {
+	query := `
+		SELECT
+			d.id, a.id, a.serial_no, m.name, b.name,
+			d.reason, d.approved_by, u.name, d.sale_value, d.certificate_ref, d.remarks,
+			d.created_by, d.created_at
+		FROM asset_disposals d
+		JOIN assets a ON a.id = d.asset_id
+		JOIN asset_models m ON m.id = a.model_id
+		JOIN asset_brands b ON b.id = m.brand_id
+		JOIN users u ON u.id = d.approved_by
+		WHERE 1=1
+	`
+
+	var args []any
+	if len(reasons) > 0 {
+		query += " AND d.reason = ANY($1)"
+		args = append(args, pq.Array(reasons))
+	}
+	query += " ORDER BY d.created_at DESC"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var disposals []models.AssetDisposal
+	for rows.Next() {
+		var d models.AssetDisposal
+		err := rows.Scan(&d.ID, &d.AssetID, &d.SerialNo, &d.ModelName, &d.BrandName,
+			&d.Reason, &d.ApprovedBy, &d.ApproverName, &d.SaleValue, &d.CertificateRef, &d.Remarks,
+			&d.CreatedBy, &d.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		disposals = append(disposals, d)
+	}
+	return disposals, nil
+}
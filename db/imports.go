@@ -0,0 +1,90 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"storex/models"
+)
+
+// ExistingSerialNos returns which of the given serial numbers are already registered
+func ExistingSerialNos(serials []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if len(serials) == 0 {
+		return existing, nil
+	}
+
+	rows, err := DB.Query(`SELECT serial_no FROM assets WHERE serial_no = ANY($1)`, pq.Array(serials))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var serial string
+		if err := rows.Scan(&serial); err != nil {
+			return nil, err
+		}
+		existing[serial] = true
+	}
+	return existing, nil
+}
+
+func CreateAssetImport(imp *models.AssetImport) (string, error) {
+	var importID string
+	err := DB.QueryRow(`
+		INSERT INTO asset_imports (file_name, checksum, total_rows, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, imp.FileName, imp.Checksum, imp.TotalRows, imp.CreatedBy).Scan(&importID)
+	if err != nil {
+		return "", err
+	}
+	return importID, nil
+}
+
+func GetAssetImport(importID string) (*models.AssetImport, error) {
+	query := `
+		SELECT id, file_name, checksum, total_rows, processed_rows, status, last_error, created_by, created_at, updated_at
+		FROM asset_imports
+		WHERE id = $1
+	`
+
+	var imp models.AssetImport
+	err := DB.QueryRow(query, importID).Scan(
+		&imp.ID,
+		&imp.FileName,
+		&imp.Checksum,
+		&imp.TotalRows,
+		&imp.ProcessedRows,
+		&imp.Status,
+		&imp.LastError,
+		&imp.CreatedBy,
+		&imp.CreatedAt,
+		&imp.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &imp, nil
+}
+
+// UpdateAssetImportProgress is run inside each batch transaction so progress and rows commit together
+func UpdateAssetImportProgress(tx *sql.Tx, importID string, processedRows int, status string) error {
+	_, err := tx.Exec(`
+		UPDATE asset_imports SET processed_rows = $2, status = $3, last_error = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, importID, processedRows, status)
+	return err
+}
+
+func FailAssetImport(importID string, lastError string) error {
+	_, err := DB.Exec(`
+		UPDATE asset_imports SET status = 'failed', last_error = $2, updated_at = NOW()
+		WHERE id = $1
+	`, importID, lastError)
+	return err
+}
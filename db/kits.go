@@ -0,0 +1,251 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"storex/models"
+)
+
+func CreateKit(tx *sql.Tx, req *models.CreateKitRequest, authUserID string) (string, error) {
+	var kitID string
+	err := tx.QueryRow(`
+		INSERT INTO onboarding_kits (name, user_type, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, req.Name, req.UserType, authUserID).Scan(&kitID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := insertKitItems(tx, kitID, req.Items); err != nil {
+		return "", err
+	}
+	return kitID, nil
+}
+
+func insertKitItems(tx *sql.Tx, kitID string, items []models.KitItem) error {
+	for i, item := range items {
+		minSpecs, err := json.Marshal(item.MinSpecs)
+		if err != nil {
+			return err
+		}
+		if item.MinSpecs == nil {
+			minSpecs = []byte("{}")
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO onboarding_kit_items (kit_id, position, asset_type, brand, model, min_specs, quantity)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, kitID, i, item.AssetType, item.Brand, item.Model, minSpecs, item.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to insert kit item %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// UpdateKit changes the kit fields that are set and replaces its items when given
+func UpdateKit(tx *sql.Tx, kitID string, req *models.UpdateKitRequest, authUserID string) (int64, error) {
+	res, err := tx.Exec(`
+		UPDATE onboarding_kits SET
+			name = COALESCE($2, name),
+			user_type = COALESCE($3, user_type),
+			updated_at = NOW(),
+			updated_by = $4
+		WHERE id = $1 AND archived_at IS NULL
+	`, kitID, req.Name, req.UserType, authUserID)
+	if err != nil {
+		return 0, err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil || updated == 0 || req.Items == nil {
+		return updated, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM onboarding_kit_items WHERE kit_id = $1`, kitID); err != nil {
+		return 0, err
+	}
+	return updated, insertKitItems(tx, kitID, req.Items)
+}
+
+// IsKitNameTaken checks the name against other active kits
+func IsKitNameTaken(tx *sql.Tx, name string, exceptKitID string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM onboarding_kits
+		WHERE LOWER(name) = LOWER($1) AND archived_at IS NULL AND id::TEXT <> $2
+	`, name, exceptKitID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func ArchiveKit(kitID string, authUserID string) (int64, error) {
+	res, err := DB.Exec(`
+		UPDATE onboarding_kits SET archived_at = NOW(), archived_by = $2
+		WHERE id = $1 AND archived_at IS NULL
+	`, kitID, authUserID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ListKits returns the active kits with their items, optionally only those for the given user types
+func ListKits(userTypes []string) ([]models.OnboardingKit, error) {
+	query := `
+		SELECT id, name, user_type, created_at
+		FROM onboarding_kits
+		WHERE archived_at IS NULL
+	`
+	var args []any
+	if len(userTypes) > 0 {
+		query += " AND user_type::TEXT = ANY($1)"
+		args = append(args, pq.Array(userTypes))
+	}
+	query += " ORDER BY user_type, name"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var kits []models.OnboardingKit
+	for rows.Next() {
+		var kit models.OnboardingKit
+		if err := rows.Scan(&kit.ID, &kit.Name, &kit.UserType, &kit.CreatedAt); err != nil {
+			return nil, err
+		}
+		kits = append(kits, kit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range kits {
+		kits[i].Items, err = fetchKitItems(kits[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return kits, nil
+}
+
+// GetKit returns an active kit with its items, nil when it does not exist
+func GetKit(kitID string) (*models.OnboardingKit, error) {
+	var kit models.OnboardingKit
+	err := DB.QueryRow(`
+		SELECT id, name, user_type, created_at
+		FROM onboarding_kits
+		WHERE id = $1 AND archived_at IS NULL
+	`, kitID).Scan(&kit.ID, &kit.Name, &kit.UserType, &kit.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	kit.Items, err = fetchKitItems(kit.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &kit, nil
+}
+
+func fetchKitItems(kitID string) ([]models.KitItem, error) {
+	rows, err := DB.Query(`
+		SELECT asset_type, brand, model, min_specs, quantity
+		FROM onboarding_kit_items
+		WHERE kit_id = $1
+		ORDER BY position
+	`, kitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.KitItem{}
+	for rows.Next() {
+		var item models.KitItem
+		var minSpecs []byte
+		if err := rows.Scan(&item.AssetType, &item.Brand, &item.Model, &minSpecs, &item.Quantity); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(minSpecs, &item.MinSpecs); err != nil {
+			return nil, fmt.Errorf("corrupt min_specs in kit %s: %w", kitID, err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// PickAvailableAssets locks up to item.Quantity available assets matching the kit line, oldest first.
+// Assets already picked for earlier lines are passed in exclude.
+func PickAvailableAssets(tx *sql.Tx, item *models.KitItem, fields []models.SpecField, exclude []string) ([]string, error) {
+	query := `
+		SELECT a.id
+		FROM assets a
+		JOIN asset_models m ON a.model_id = m.id
+		JOIN asset_brands b ON m.brand_id = b.id
+		JOIN asset_specs sp ON sp.id = a.specs_id
+		JOIN asset_status s ON s.asset_id = a.id AND s.archived_at IS NULL
+		WHERE s.status = 'available' AND a.archived_at IS NULL
+		  AND m.asset_type = $1 AND a.id::TEXT <> ALL($2)
+	`
+	if exclude == nil {
+		exclude = []string{} // a NULL array would match nothing
+	}
+	args := []any{item.AssetType, pq.Array(exclude)}
+	argIndex := 3
+
+	if item.Brand != nil {
+		query += fmt.Sprintf(" AND LOWER(b.name) = LOWER($%d)", argIndex)
+		args = append(args, *item.Brand)
+		argIndex++
+	}
+	if item.Model != nil {
+		query += fmt.Sprintf(" AND LOWER(m.name) = LOWER($%d)", argIndex)
+		args = append(args, *item.Model)
+		argIndex++
+	}
+
+	// spec keys are bound as parameters, numeric fields compare as minimums
+	for _, field := range fields {
+		val, ok := item.MinSpecs[field.Name]
+		if !ok {
+			continue
+		}
+		if field.Type == "int" || field.Type == "number" {
+			query += fmt.Sprintf(" AND ("+effectiveSpecsSQL+"->>$%d::TEXT)::NUMERIC >= $%d", argIndex, argIndex+1)
+		} else {
+			query += fmt.Sprintf(" AND "+effectiveSpecsSQL+"->>$%d::TEXT = $%d", argIndex, argIndex+1)
+		}
+		args = append(args, field.Name, fmt.Sprint(val))
+		argIndex += 2
+	}
+
+	query += fmt.Sprintf(" ORDER BY a.created_at LIMIT $%d FOR UPDATE OF s", argIndex)
+	args = append(args, item.Quantity)
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assetIDs []string
+	for rows.Next() {
+		var assetID string
+		if err := rows.Scan(&assetID); err != nil {
+			return nil, err
+		}
+		assetIDs = append(assetIDs, assetID)
+	}
+	return assetIDs, rows.Err()
+}
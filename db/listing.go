@@ -0,0 +1,99 @@
+package db
+
+import (
+	"fmt"
+	"storex/models"
+	"strings"
+)
+
+// listingSQL is what a list query needs for sorting and paging
+type listingSQL struct {
+	cond   string // keyset condition of the cursor, empty without one
+	order  string // ORDER BY, LIMIT and OFFSET
+	cursor string // ARRAY of the sort values of a row, to make the next cursor from
+}
+
+// buildListingSQL sorts by the asked keys, or the defaults, and idColumn to break ties.
+// Walking backward flips every direction, the caller reverses the rows.
+func buildListingSQL(list *models.ListParams, defaults []models.SortKey, idColumn string, argIndex int) (listingSQL, []any, int) {
+	keys := list.Sort
+	if len(keys) == 0 {
+		keys = defaults
+	}
+	keys = append(keys[:len(keys):len(keys)], models.SortKey{
+		SortField: models.SortField{Name: "id", Column: idColumn, Type: "UUID"},
+		Desc:      keys[len(keys)-1].Desc,
+	})
+
+	var listing listingSQL
+	var args []any
+
+	var order, values []string
+	for _, key := range keys {
+		dir := "ASC"
+		if key.Desc != list.Backward {
+			dir = "DESC"
+		}
+		order = append(order, key.Column+" "+dir)
+		values = append(values, key.Column+"::TEXT")
+	}
+	listing.cursor = "ARRAY[" + strings.Join(values, ", ") + "]"
+
+	// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with < on descending keys
+	if len(list.After) > 0 {
+		var alternatives []string
+		for i, key := range keys {
+			var terms []string
+			for j, prev := range keys[:i] {
+				terms = append(terms, fmt.Sprintf("%s = $%d::%s", prev.Column, argIndex+j, prev.Type))
+			}
+			op := ">"
+			if key.Desc != list.Backward {
+				op = "<"
+			}
+			terms = append(terms, fmt.Sprintf("%s %s $%d::%s", key.Column, op, argIndex+i, key.Type))
+			alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+		}
+		listing.cond = " AND (" + strings.Join(alternatives, " OR ") + ")"
+		for _, v := range list.After {
+			args = append(args, v)
+		}
+		argIndex += len(list.After)
+	}
+
+	listing.order = fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", strings.Join(order, ", "), argIndex, argIndex+1)
+	args = append(args, list.Limit, list.Offset)
+	argIndex += 2
+
+	return listing, args, argIndex
+}
+
+// facetCounts counts the distinct rows (by idColumn) per value of each facet. from holds the
+// tables of the listing, where its filters, so the counts follow what the listing shows.
+func facetCounts(facets []models.FacetField, from string, where string, idColumn string, args []any) (map[string][]models.FacetCount, error) {
+	counts := map[string][]models.FacetCount{}
+	for _, facet := range facets {
+		query := fmt.Sprintf(`SELECT %s, COUNT(DISTINCT %s) %s %s %s AND %s IS NOT NULL GROUP BY 1 ORDER BY 2 DESC, 1`,
+			facet.Column, idColumn, from, facet.Join, where, facet.Column)
+
+		rows, err := DB.Query(query, args...)
+		if err != nil {
+			return nil, err
+		}
+		values := []models.FacetCount{}
+		for rows.Next() {
+			var fc models.FacetCount
+			if err := rows.Scan(&fc.Value, &fc.Count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			values = append(values, fc)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		counts[facet.Name] = values
+	}
+	return counts, nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"reflect"
+	"storex/models"
+	"testing"
+)
+
+var (
+	sortBySerial = models.SortField{Name: "serial_no", Column: "a.serial_no", Type: "TEXT"}
+	sortByDate   = models.SortField{Name: "purchased_date", Column: "a.purchased_date", Type: "DATE"}
+)
+
+func TestBuildListingSQLDefaults(t *testing.T) {
+	list := &models.ListParams{Limit: 20, Offset: 40}
+	defaults := []models.SortKey{{SortField: sortByDate, Desc: true}}
+
+	listing, args, next := buildListingSQL(list, defaults, "a.id", 3)
+
+	if listing.cond != "" {
+		t.Errorf("got condition %q without a cursor", listing.cond)
+	}
+	if want := " ORDER BY a.purchased_date DESC, a.id DESC LIMIT $3 OFFSET $4"; listing.order != want {
+		t.Errorf("got order %q, want %q", listing.order, want)
+	}
+	if want := "ARRAY[a.purchased_date::TEXT, a.id::TEXT]"; listing.cursor != want {
+		t.Errorf("got cursor %q, want %q", listing.cursor, want)
+	}
+	if !reflect.DeepEqual(args, []any{20, 40}) || next != 5 {
+		t.Errorf("got args %v and next index %d", args, next)
+	}
+
+	// the id tie breaker must not leak into the defaults of the next call
+	if len(defaults) != 1 {
+		t.Errorf("defaults grew to %v", defaults)
+	}
+}
+
+func TestBuildListingSQLCursor(t *testing.T) {
+	list := &models.ListParams{
+		Sort:  []models.SortKey{{SortField: sortBySerial}, {SortField: sortByDate, Desc: true}},
+		Limit: 10,
+		After: []string{"SN-1", "2024-01-15", "8c5d6f1e-3b1a-4c7e-9f0a-1d2e3f4a5b6c"},
+	}
+
+	listing, args, next := buildListingSQL(list, nil, "a.id", 2)
+
+	wantCond := " AND ((a.serial_no > $2::TEXT)" +
+		" OR (a.serial_no = $2::TEXT AND a.purchased_date < $3::DATE)" +
+		" OR (a.serial_no = $2::TEXT AND a.purchased_date = $3::DATE AND a.id < $4::UUID))"
+	if listing.cond != wantCond {
+		t.Errorf("got condition\n%s\nwant\n%s", listing.cond, wantCond)
+	}
+	if want := " ORDER BY a.serial_no ASC, a.purchased_date DESC, a.id DESC LIMIT $5 OFFSET $6"; listing.order != want {
+		t.Errorf("got order %q, want %q", listing.order, want)
+	}
+	wantArgs := []any{"SN-1", "2024-01-15", "8c5d6f1e-3b1a-4c7e-9f0a-1d2e3f4a5b6c", 10, 0}
+	if !reflect.DeepEqual(args, wantArgs) || next != 7 {
+		t.Errorf("got args %v and next index %d", args, next)
+	}
+}
+
+func TestBuildListingSQLBackward(t *testing.T) {
+	list := &models.ListParams{
+		Sort:     []models.SortKey{{SortField: sortBySerial}},
+		Limit:    10,
+		After:    []string{"SN-1", "8c5d6f1e-3b1a-4c7e-9f0a-1d2e3f4a5b6c"},
+		Backward: true,
+	}
+
+	listing, _, _ := buildListingSQL(list, nil, "a.id", 1)
+
+	if want := " AND ((a.serial_no < $1::TEXT) OR (a.serial_no = $1::TEXT AND a.id < $2::UUID))"; listing.cond != want {
+		t.Errorf("got condition %q, want %q", listing.cond, want)
+	}
+	if want := " ORDER BY a.serial_no DESC, a.id DESC LIMIT $3 OFFSET $4"; listing.order != want {
+		t.Errorf("got order %q, want %q", listing.order, want)
+	}
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"storex/models"
+)
+
+// locationTreeCTE walks the hierarchy from the offices down, giving every location its full path,
+// the office it belongs to and the ids of all its ancestors. Archived locations are kept so history resolves.
+const locationTreeCTE = `
+	WITH RECURSIVE location_tree AS (
+		SELECT id, name, kind, parent_id, city, created_at, archived_at,
+			name::TEXT AS path,
+			CASE WHEN kind = 'office' THEN id END AS office_id,
+			city AS office_city,
+			ARRAY[id] AS lineage
+		FROM locations
+		WHERE parent_id IS NULL
+		UNION ALL
+		SELECT l.id, l.name, l.kind, l.parent_id, l.city, l.created_at, l.archived_at,
+			lt.path || ' / ' || l.name,
+			lt.office_id,
+			lt.office_city,
+			lt.lineage || l.id
+		FROM locations l
+		JOIN location_tree lt ON l.parent_id = lt.id
+	)`
+
+// Default locations of a new status row when the request names none, $1 being the asset id.
+// An assignment puts the asset with the employee, anything else leaves it where it was last stocked.
+const (
+	remoteLocationSQL = `(SELECT id FROM locations WHERE kind = 'remote' AND archived_at IS NULL)`
+
+	lastStockedLocationSQL = `(
+		SELECT ps.location_id
+		FROM asset_status ps
+		JOIN locations pl ON pl.id = ps.location_id
+		WHERE ps.asset_id = $1 AND pl.kind <> 'remote'
+		ORDER BY ps.created_at DESC
+		LIMIT 1
+	)`
+)
+
+const locationColumns = `
+	lt.id, lt.name, lt.kind, lt.parent_id, lt.path, lt.office_id, lt.office_city, lt.created_at,
+	(SELECT COUNT(*)
+		FROM asset_status s
+		JOIN location_tree below ON below.id = s.location_id
+		WHERE s.archived_at IS NULL AND s.status <> 'disposed' AND lt.id = ANY(below.lineage))
+	FROM location_tree lt`
+
+func scanLocation(row interface{ Scan(...any) error }, loc *models.Location) error {
+	return row.Scan(&loc.ID, &loc.Name, &loc.Kind, &loc.ParentID, &loc.Path, &loc.OfficeID, &loc.City,
+		&loc.CreatedAt, &loc.Assets)
+}
+
+// ListLocations returns the active locations in path order, optionally only those of the given kinds
+func ListLocations(kinds []string) ([]models.Location, error) {
+	query := locationTreeCTE + " SELECT" + locationColumns + " WHERE lt.archived_at IS NULL"
+	var args []any
+	if len(kinds) > 0 {
+		query += " AND lt.kind::TEXT = ANY($1)"
+		args = append(args, pq.Array(kinds))
+	}
+	query += " ORDER BY lt.path"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []models.Location
+	for rows.Next() {
+		var loc models.Location
+		if err := scanLocation(rows, &loc); err != nil {
+			return nil, err
+		}
+		locations = append(locations, loc)
+	}
+	return locations, rows.Err()
+}
+
+// GetLocation returns an active location, nil when it does not exist or is archived
+func GetLocation(locationID string) (*models.Location, error) {
+	var loc models.Location
+	query := locationTreeCTE + " SELECT" + locationColumns + " WHERE lt.id::TEXT = $1 AND lt.archived_at IS NULL"
+	if err := scanLocation(DB.QueryRow(query, locationID), &loc); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &loc, nil
+}
+
+func CreateLocation(tx *sql.Tx, req *models.CreateLocationRequest, authUserID string) (string, error) {
+	var locationID string
+	err := tx.QueryRow(`
+		INSERT INTO locations (name, kind, parent_id, city, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, req.Name, req.Kind, req.ParentID, req.City, authUserID).Scan(&locationID)
+	if err != nil {
+		return "", err
+	}
+	return locationID, nil
+}
+
+// IsLocationNameTaken checks the name against the other active locations under the same parent
+func IsLocationNameTaken(tx *sql.Tx, parentID *string, name string, exceptLocationID string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM locations
+		WHERE parent_id IS NOT DISTINCT FROM $1 AND LOWER(name) = LOWER($2)
+		  AND archived_at IS NULL AND id::TEXT <> $3
+	`, parentID, name, exceptLocationID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func UpdateLocation(tx *sql.Tx, locationID string, req *models.UpdateLocationRequest, authUserID string) (int64, error) {
+	res, err := tx.Exec(`
+		UPDATE locations SET
+			name = COALESCE($2, name),
+			city = COALESCE($3, city),
+			updated_at = NOW(),
+			updated_by = $4
+		WHERE id = $1 AND archived_at IS NULL
+	`, locationID, req.Name, req.City, authUserID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// CountLocationUsage returns how many active locations sit directly below the location
+// and how many assets have it on their active status
+func CountLocationUsage(tx *sql.Tx, locationID string) (int, int, error) {
+	var children, assets int
+	err := tx.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM locations WHERE parent_id = $1 AND archived_at IS NULL),
+			(SELECT COUNT(*) FROM asset_status WHERE location_id = $1 AND archived_at IS NULL AND status <> 'disposed')
+	`, locationID).Scan(&children, &assets)
+	return children, assets, err
+}
+
+func ArchiveLocation(tx *sql.Tx, locationID string, authUserID string) (int64, error) {
+	res, err := tx.Exec(`
+		UPDATE locations SET archived_at = NOW(), archived_by = $2
+		WHERE id = $1 AND archived_at IS NULL AND kind <> 'remote'
+	`, locationID, authUserID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SetActiveStatusLocation records where the asset is on its active status row
+func SetActiveStatusLocation(tx *sql.Tx, assetID string, locationID string) error {
+	_, err := tx.Exec(`
+		UPDATE asset_status SET location_id = $2
+		WHERE asset_id = $1 AND archived_at IS NULL
+	`, assetID, locationID)
+	return err
+}
+
+// ListOfficeStock counts the available assets at every office, including its floors, rooms and cabinets
+func ListOfficeStock() ([]models.OfficeStock, error) {
+	rows, err := DB.Query(locationTreeCTE + `
+		SELECT o.id, o.name, o.city, m.asset_type, COUNT(s.id)
+		FROM locations o
+		LEFT JOIN location_tree lt ON lt.office_id = o.id
+		LEFT JOIN asset_status s ON s.location_id = lt.id AND s.archived_at IS NULL AND s.status = 'available'
+		LEFT JOIN assets a ON a.id = s.asset_id
+		LEFT JOIN asset_models m ON m.id = a.model_id
+		WHERE o.kind = 'office' AND o.archived_at IS NULL
+		GROUP BY o.id, o.name, o.city, m.asset_type
+		ORDER BY o.city, o.name, o.id, m.asset_type
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []models.OfficeStock
+	for rows.Next() {
+		var office models.OfficeStock
+		var assetType sql.NullString
+		var count int
+		if err := rows.Scan(&office.OfficeID, &office.OfficeName, &office.City, &assetType, &count); err != nil {
+			return nil, err
+		}
+
+		// rows come grouped by office, one per asset type in stock
+		if n := len(stock); n == 0 || stock[n-1].OfficeID != office.OfficeID {
+			office.ByType = map[string]int{}
+			stock = append(stock, office)
+		}
+		if assetType.Valid {
+			last := &stock[len(stock)-1]
+			last.ByType[assetType.String] = count
+			last.Available += count
+		}
+	}
+	return stock, rows.Err()
+}
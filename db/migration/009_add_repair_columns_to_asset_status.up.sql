@@ -0,0 +1,9 @@
+CREATE TYPE repair_outcome_type AS ENUM ('repaired', 'unrepairable');
+
+ALTER TABLE asset_status
+    ADD COLUMN remarks TEXT,
+    ADD COLUMN repair_outcome repair_outcome_type; -- filled when an asset comes back from service
+
+CREATE INDEX idx_asset_status_service_active
+    ON asset_status(sent_to_service)
+    WHERE archived_at IS NULL;
@@ -0,0 +1,19 @@
+ALTER TABLE services
+    ADD COLUMN created_by UUID REFERENCES users(id),
+    ADD COLUMN updated_at TIMESTAMPTZ,
+    ADD COLUMN updated_by UUID REFERENCES users(id),
+    ADD COLUMN archived_at TIMESTAMPTZ,
+    ADD COLUMN archived_by UUID REFERENCES users(id);
+
+-- vendors entered more than once under the same email are archived before emails become unique,
+-- the earliest entry stays active and keeps its history
+UPDATE services s SET archived_at = NOW()
+WHERE EXISTS (
+    SELECT 1 FROM services o
+    WHERE TRIM(LOWER(o.email)) = TRIM(LOWER(s.email))
+      AND (o.created_at, o.id) < (s.created_at, s.id)
+);
+
+-- indexes for text search
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_active_service_emails ON services(TRIM(LOWER(email))) WHERE archived_at IS NULL;
+CREATE INDEX idx_services_lower_name ON services(LOWER(name));
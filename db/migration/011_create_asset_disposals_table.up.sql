@@ -0,0 +1,16 @@
+CREATE TYPE disposal_reason AS ENUM ('e_waste', 'sold', 'lost', 'stolen', 'returned_to_client');
+
+CREATE TABLE IF NOT EXISTS asset_disposals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    asset_id UUID REFERENCES assets(id) NOT NULL,
+    reason disposal_reason NOT NULL,
+    approved_by UUID REFERENCES users(id) NOT NULL,
+    sale_value NUMERIC(12, 2), -- only meaningful when reason = 'sold'
+    certificate_ref TEXT,       -- e-waste / sale certificate number
+    remarks TEXT,
+    created_by UUID REFERENCES users(id) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX uniq_asset_disposal ON asset_disposals(asset_id);
+CREATE INDEX idx_asset_disposals_reason ON asset_disposals(reason);
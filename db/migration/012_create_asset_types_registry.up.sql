@@ -0,0 +1,89 @@
+-- Asset types and their spec schema live in the database so new types can be added at runtime.
+CREATE TABLE IF NOT EXISTS asset_types (
+    name TEXT PRIMARY KEY,
+    fields JSONB NOT NULL DEFAULT '[]', -- [{"name", "type", "required", "enum", "min", "max"}]
+    built_in BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    updated_at TIMESTAMPTZ,
+    updated_by UUID REFERENCES users(id),
+    archived_at TIMESTAMPTZ,
+    archived_by UUID REFERENCES users(id)
+);
+
+INSERT INTO asset_types (name, built_in, fields) VALUES
+('laptop', true, '[
+    {"name": "processor", "type": "string", "required": true},
+    {"name": "ram_gb", "type": "int", "required": true, "min": 1},
+    {"name": "storage_gb", "type": "int", "required": true, "min": 1},
+    {"name": "storage_type", "type": "string", "required": true, "enum": ["HDD", "SSD"]},
+    {"name": "screen_size_inch", "type": "number", "required": true, "min": 1},
+    {"name": "has_charger", "type": "bool"}
+]'),
+('mouse', true, '[
+    {"name": "type", "type": "string", "required": true, "enum": ["wired", "wireless"]},
+    {"name": "dpi", "type": "int", "min": 1},
+    {"name": "number_of_buttons", "type": "int", "min": 1}
+]'),
+('monitor', true, '[
+    {"name": "screen_size_inch", "type": "number", "required": true, "min": 1},
+    {"name": "resolution", "type": "string"},
+    {"name": "refresh_rate", "type": "int", "min": 1},
+    {"name": "panel_type", "type": "string"}
+]'),
+('hard_disk', true, '[
+    {"name": "capacity_gb", "type": "int", "required": true, "min": 1},
+    {"name": "type", "type": "string", "required": true, "enum": ["HDD", "SSD"]}
+]'),
+('pen_drive', true, '[
+    {"name": "capacity_gb", "type": "int", "required": true, "min": 1},
+    {"name": "usb_version", "type": "string"}
+]'),
+('mobile', true, '[
+    {"name": "os", "type": "string", "required": true},
+    {"name": "ram_gb", "type": "int", "min": 1},
+    {"name": "storage_gb", "type": "int", "min": 1},
+    {"name": "has_dual_sim", "type": "bool"}
+]'),
+('sim', true, '[
+    {"name": "carrier", "type": "string", "required": true},
+    {"name": "phone_number", "type": "string"},
+    {"name": "data_limit_gb", "type": "int", "min": 0}
+]'),
+('accessories', true, '[
+    {"name": "name", "type": "string", "required": true},
+    {"name": "description", "type": "string"},
+    {"name": "compatible_with", "type": "string"}
+]');
+
+ALTER TABLE asset_models ALTER COLUMN asset_type TYPE TEXT USING asset_type::TEXT;
+ALTER TABLE asset_models
+    ADD CONSTRAINT fk_asset_models_asset_type FOREIGN KEY (asset_type) REFERENCES asset_types(name);
+DROP TYPE asset_type;
+
+-- All specs are stored as JSONB validated against asset_types.fields
+CREATE TABLE IF NOT EXISTS asset_specs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    asset_type TEXT NOT NULL REFERENCES asset_types(name),
+    specs JSONB NOT NULL DEFAULT '{}'
+);
+
+-- keep the existing spec ids so assets.specs_id stays valid
+INSERT INTO asset_specs (id, asset_type, specs) SELECT id, 'laptop', jsonb_strip_nulls(to_jsonb(t) - 'id') FROM laptop_specs t;
+INSERT INTO asset_specs (id, asset_type, specs) SELECT id, 'mouse', jsonb_strip_nulls(to_jsonb(t) - 'id') FROM mouse_specs t;
+INSERT INTO asset_specs (id, asset_type, specs) SELECT id, 'monitor', jsonb_strip_nulls(to_jsonb(t) - 'id') FROM monitor_specs t;
+INSERT INTO asset_specs (id, asset_type, specs) SELECT id, 'hard_disk', jsonb_strip_nulls(to_jsonb(t) - 'id') FROM hard_disk_specs t;
+INSERT INTO asset_specs (id, asset_type, specs) SELECT id, 'pen_drive', jsonb_strip_nulls(to_jsonb(t) - 'id') FROM pen_drive_specs t;
+INSERT INTO asset_specs (id, asset_type, specs) SELECT id, 'mobile', jsonb_strip_nulls(to_jsonb(t) - 'id') FROM mobile_specs t;
+INSERT INTO asset_specs (id, asset_type, specs) SELECT id, 'sim', jsonb_strip_nulls(to_jsonb(t) - 'id') FROM sim_specs t;
+INSERT INTO asset_specs (id, asset_type, specs) SELECT id, 'accessories', jsonb_strip_nulls(to_jsonb(t) - 'id') FROM accessory_specs t;
+
+DROP TABLE laptop_specs, mouse_specs, monitor_specs, hard_disk_specs, pen_drive_specs, mobile_specs, sim_specs, accessory_specs;
+
+CREATE INDEX idx_asset_specs_specs ON asset_specs USING GIN (specs);
+CREATE INDEX idx_asset_specs_asset_type ON asset_specs(asset_type);
+CREATE UNIQUE INDEX uniq_sim_phone_number ON asset_specs ((specs->>'phone_number'))
+    WHERE asset_type = 'sim';
+
+ALTER TABLE assets
+    ADD CONSTRAINT fk_assets_specs_id FOREIGN KEY (specs_id) REFERENCES asset_specs(id);
@@ -0,0 +1,15 @@
+CREATE TYPE import_status AS ENUM ('in_progress', 'completed', 'failed');
+
+-- progress of batched imports so a failed import can be resumed
+CREATE TABLE IF NOT EXISTS asset_imports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    file_name TEXT NOT NULL,
+    checksum TEXT NOT NULL, -- sha256 of the uploaded file, resumes must send the same file
+    total_rows INTEGER NOT NULL,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    status import_status NOT NULL DEFAULT 'in_progress',
+    last_error TEXT,
+    created_by UUID REFERENCES users(id) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ
+);
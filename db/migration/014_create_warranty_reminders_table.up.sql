@@ -0,0 +1,11 @@
+-- one row per reminder sent so restarts never send the same reminder twice
+CREATE TABLE IF NOT EXISTS warranty_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    asset_id UUID REFERENCES assets(id) NOT NULL,
+    window_days INTEGER NOT NULL,
+    warranty_exp_date TIMESTAMPTZ NOT NULL, -- a changed expiry date starts a new reminder cycle
+    sent_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX uniq_warranty_reminder ON warranty_reminders(asset_id, window_days, warranty_exp_date);
+CREATE INDEX idx_assets_warranty_exp_date ON assets(warranty_exp_date) WHERE archived_at IS NULL;
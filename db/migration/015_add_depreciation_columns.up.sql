@@ -0,0 +1,21 @@
+CREATE TYPE depreciation_method AS ENUM ('straight_line', 'declining_balance');
+
+-- default policy per asset type
+ALTER TABLE asset_types
+    ADD COLUMN depreciation_method depreciation_method,
+    ADD COLUMN useful_life_months INTEGER CHECK (useful_life_months > 0),
+    ADD COLUMN declining_rate NUMERIC(5, 4) CHECK (declining_rate > 0 AND declining_rate <= 1); -- annual, NULL = double declining
+
+UPDATE asset_types SET depreciation_method = 'straight_line', useful_life_months = 36 WHERE name IN ('laptop', 'hard_disk');
+UPDATE asset_types SET depreciation_method = 'straight_line', useful_life_months = 60 WHERE name = 'monitor';
+UPDATE asset_types SET depreciation_method = 'declining_balance', useful_life_months = 24, declining_rate = 0.40 WHERE name = 'mobile';
+
+-- cost of the asset and an optional per asset override of the type policy
+ALTER TABLE assets
+    ADD COLUMN purchase_cost NUMERIC(12, 2) CHECK (purchase_cost >= 0),
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'INR',
+    ADD COLUMN salvage_value NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (salvage_value >= 0),
+    ADD COLUMN depreciation_method depreciation_method,
+    ADD COLUMN useful_life_months INTEGER CHECK (useful_life_months > 0),
+    ADD COLUMN declining_rate NUMERIC(5, 4) CHECK (declining_rate > 0 AND declining_rate <= 1),
+    ADD CONSTRAINT assets_salvage_within_cost CHECK (purchase_cost IS NULL OR salvage_value <= purchase_cost);
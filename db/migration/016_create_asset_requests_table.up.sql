@@ -0,0 +1,29 @@
+CREATE TYPE asset_request_status AS ENUM ('pending', 'approved', 'rejected', 'fulfilled', 'cancelled');
+
+-- requests employees file for new or replacement assets
+CREATE TABLE IF NOT EXISTS asset_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requested_by UUID REFERENCES users(id) NOT NULL,
+    asset_type TEXT REFERENCES asset_types(name), -- optional, e.g. a charger may not map to a type
+    description TEXT NOT NULL,
+    status asset_request_status NOT NULL DEFAULT 'pending',
+    fulfilled_asset_id UUID REFERENCES assets(id),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ,
+    updated_by UUID REFERENCES users(id)
+);
+
+CREATE INDEX idx_asset_requests_requested_by ON asset_requests(requested_by);
+CREATE INDEX idx_asset_requests_status ON asset_requests(status);
+
+-- status changes and comments on a request, status is NULL for plain comments
+CREATE TABLE IF NOT EXISTS asset_request_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    request_id UUID REFERENCES asset_requests(id) NOT NULL,
+    actor_id UUID REFERENCES users(id) NOT NULL,
+    status asset_request_status,
+    comment TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_asset_request_events_request_id ON asset_request_events(request_id, created_at);
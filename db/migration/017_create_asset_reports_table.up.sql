@@ -0,0 +1,32 @@
+CREATE TYPE asset_report_type AS ENUM ('damaged', 'lost', 'stolen', 'return');
+CREATE TYPE asset_report_status AS ENUM ('open', 'accepted', 'rejected');
+
+-- problems and returns reported by the employee holding an asset
+CREATE TABLE IF NOT EXISTS asset_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    asset_id UUID REFERENCES assets(id) NOT NULL,
+    reported_by UUID REFERENCES users(id) NOT NULL,
+    report_type asset_report_type NOT NULL,
+    description TEXT NOT NULL,
+    return_date DATE, -- when the employee plans to hand the asset back, only for returns
+    status asset_report_status NOT NULL DEFAULT 'open',
+    resolution_remarks TEXT,
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX uniq_open_asset_report ON asset_reports(asset_id) WHERE status = 'open';
+CREATE INDEX idx_asset_reports_reported_by ON asset_reports(reported_by);
+CREATE INDEX idx_asset_reports_status ON asset_reports(status);
+
+CREATE TABLE IF NOT EXISTS asset_report_photos (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    report_id UUID REFERENCES asset_reports(id) NOT NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_asset_report_photos_report_id ON asset_report_photos(report_id);
@@ -0,0 +1,4 @@
+-- an assignment row created by a transfer keeps who handed the asset over and why
+ALTER TABLE asset_status
+    ADD COLUMN transferred_from UUID REFERENCES users(id),
+    ADD COLUMN transfer_reason TEXT;
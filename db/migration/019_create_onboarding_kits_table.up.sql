@@ -0,0 +1,28 @@
+-- named bundles of asset types handed out together when someone joins
+CREATE TABLE IF NOT EXISTS onboarding_kits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    user_type user_type NOT NULL,
+    created_by UUID REFERENCES users(id) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ,
+    updated_by UUID REFERENCES users(id),
+    archived_at TIMESTAMPTZ,
+    archived_by UUID REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX uniq_active_kit_name ON onboarding_kits(LOWER(name)) WHERE archived_at IS NULL;
+CREATE INDEX idx_onboarding_kits_user_type ON onboarding_kits(user_type) WHERE archived_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS onboarding_kit_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kit_id UUID REFERENCES onboarding_kits(id) NOT NULL,
+    position INTEGER NOT NULL,
+    asset_type TEXT REFERENCES asset_types(name) NOT NULL,
+    brand TEXT,  -- optional brand name to match
+    model TEXT,  -- optional model name to match
+    min_specs JSONB NOT NULL DEFAULT '{}', -- numeric fields are minimums, other fields must match
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0)
+);
+
+CREATE INDEX idx_onboarding_kit_items_kit_id ON onboarding_kit_items(kit_id, position);
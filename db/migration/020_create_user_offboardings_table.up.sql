@@ -0,0 +1,30 @@
+CREATE TYPE offboarding_status AS ENUM ('open', 'completed', 'cancelled');
+CREATE TYPE offboarding_item_status AS ENUM ('pending', 'returned', 'lost');
+
+-- exit process of a user, the user is archived once every held asset is cleared
+CREATE TABLE IF NOT EXISTS user_offboardings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) NOT NULL,
+    last_working_date DATE NOT NULL,
+    status offboarding_status NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id) NOT NULL,
+    closed_at TIMESTAMPTZ,
+    closed_by UUID REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX uniq_open_user_offboarding ON user_offboardings(user_id) WHERE status = 'open';
+CREATE INDEX idx_user_offboardings_status ON user_offboardings(status);
+
+-- checklist, one row per asset the user held when the process was opened
+CREATE TABLE IF NOT EXISTS user_offboarding_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    offboarding_id UUID REFERENCES user_offboardings(id) NOT NULL,
+    asset_id UUID REFERENCES assets(id) NOT NULL,
+    status offboarding_item_status NOT NULL DEFAULT 'pending',
+    remarks TEXT,
+    cleared_at TIMESTAMPTZ,
+    cleared_by UUID REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX uniq_offboarding_item_asset ON user_offboarding_items(offboarding_id, asset_id);
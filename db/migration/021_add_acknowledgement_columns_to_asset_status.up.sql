@@ -0,0 +1,11 @@
+-- an assignment is pending until the employee confirms receipt with a typed signature
+ALTER TABLE asset_status
+    ADD COLUMN acknowledged_at TIMESTAMPTZ,
+    ADD COLUMN acknowledgement_signature TEXT;
+
+-- handovers made before acknowledgements existed are taken as received
+UPDATE asset_status SET acknowledged_at = created_at WHERE status = 'assigned';
+
+CREATE INDEX idx_asset_status_unacknowledged
+    ON asset_status(assigned_to_user)
+    WHERE status = 'assigned' AND archived_at IS NULL AND acknowledged_at IS NULL;
@@ -0,0 +1,23 @@
+CREATE TYPE receipt_kind AS ENUM ('handover', 'return', 'statement');
+
+-- organisation wording of the PDF receipts, title / intro / footer are Go text/template strings
+CREATE TABLE IF NOT EXISTS receipt_templates (
+    kind receipt_kind PRIMARY KEY,
+    organisation TEXT NOT NULL,
+    title TEXT NOT NULL,
+    intro TEXT NOT NULL DEFAULT '',
+    footer TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ,
+    updated_by UUID REFERENCES users(id)
+);
+
+INSERT INTO receipt_templates (kind, organisation, title, intro, footer) VALUES
+('handover', 'Storex', 'Asset Handover Receipt',
+ 'This confirms that {{.EmployeeName}} ({{.EmployeeEmail}}) received the asset below from {{.Organisation}} on {{.Date.Format "02 Jan 2006"}}.',
+ 'The asset remains the property of {{.Organisation}} and must be returned on request or when leaving the organisation.'),
+('return', 'Storex', 'Asset Return Receipt',
+ 'This confirms that {{.EmployeeName}} ({{.EmployeeEmail}}) returned the asset below to {{.Organisation}} on {{.Date.Format "02 Jan 2006"}}.',
+ 'Please keep this receipt for your records.'),
+('statement', 'Storex', 'Statement of Assets Held',
+ 'As of {{.Date.Format "02 Jan 2006"}}, {{.EmployeeName}} ({{.EmployeeEmail}}) holds the following {{len .Assets}} asset(s) of {{.Organisation}}.',
+ 'Report any difference to the asset team.');
@@ -0,0 +1,46 @@
+CREATE TYPE audit_status AS ENUM ('open', 'closed');
+CREATE TYPE audit_condition AS ENUM ('good', 'damaged', 'not_working');
+
+-- physical inventory campaign, an empty scope array means every asset
+CREATE TABLE IF NOT EXISTS asset_audits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    scope_asset_types TEXT[] NOT NULL DEFAULT '{}',
+    scope_owned_by TEXT[] NOT NULL DEFAULT '{}',
+    status audit_status NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id) NOT NULL,
+    closed_at TIMESTAMPTZ,
+    closed_by UUID REFERENCES users(id)
+);
+
+CREATE INDEX idx_asset_audits_status ON asset_audits(status);
+
+-- assets in scope when the audit was opened, with their active status at that time
+CREATE TABLE IF NOT EXISTS asset_audit_expected (
+    audit_id UUID REFERENCES asset_audits(id) NOT NULL,
+    asset_id UUID REFERENCES assets(id) NOT NULL,
+    status asset_status_type,
+    assigned_to_user UUID REFERENCES users(id),
+    PRIMARY KEY (audit_id, asset_id)
+);
+
+-- what was seen, asset_id is NULL when the scanned code matched no asset
+CREATE TABLE IF NOT EXISTS asset_audit_scans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    audit_id UUID REFERENCES asset_audits(id) NOT NULL,
+    asset_id UUID REFERENCES assets(id),
+    code TEXT NOT NULL,
+    location TEXT,
+    condition audit_condition NOT NULL DEFAULT 'good',
+    seen_with_user UUID REFERENCES users(id), -- who had the asset, NULL when found in storage
+    recorded_status asset_status_type,        -- active asset_status when scanned
+    recorded_user UUID REFERENCES users(id),
+    remarks TEXT,
+    scanned_by UUID REFERENCES users(id) NOT NULL,
+    scanned_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX uniq_audit_scan_asset ON asset_audit_scans(audit_id, asset_id) WHERE asset_id IS NOT NULL;
+CREATE INDEX idx_asset_audit_scans_asset_id ON asset_audit_scans(asset_id);
+CREATE UNIQUE INDEX uniq_audit_scan_unknown_code ON asset_audit_scans(audit_id, LOWER(code)) WHERE asset_id IS NULL;
@@ -0,0 +1,35 @@
+CREATE TYPE location_kind AS ENUM ('office', 'floor', 'room', 'cabinet', 'remote');
+
+-- offices at the top, then floors, rooms and storage cabinets; city is set on offices
+CREATE TABLE IF NOT EXISTS locations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    kind location_kind NOT NULL,
+    parent_id UUID REFERENCES locations(id),
+    city TEXT,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    updated_at TIMESTAMPTZ,
+    updated_by UUID REFERENCES users(id),
+    archived_at TIMESTAMPTZ,
+    archived_by UUID REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX uniq_active_location_name
+    ON locations(COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'), LOWER(name))
+    WHERE archived_at IS NULL;
+
+CREATE INDEX idx_locations_parent_id ON locations(parent_id) WHERE archived_at IS NULL;
+
+-- assets assigned to employees are with them, wherever they work from
+CREATE UNIQUE INDEX uniq_remote_location ON locations(kind) WHERE kind = 'remote' AND archived_at IS NULL;
+
+INSERT INTO locations (name, kind) VALUES ('Remote with employee', 'remote');
+
+-- where the asset was when the status was recorded, NULL for history older than locations
+ALTER TABLE asset_status
+    ADD COLUMN location_id UUID REFERENCES locations(id);
+
+CREATE INDEX idx_asset_status_location_active
+    ON asset_status(location_id)
+    WHERE archived_at IS NULL;
@@ -0,0 +1,15 @@
+-- brands and models are managed directly now, not only created as a side effect of CreateAsset
+ALTER TABLE asset_brands
+    ADD COLUMN created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN created_by UUID REFERENCES users(id),
+    ADD COLUMN updated_at TIMESTAMPTZ,
+    ADD COLUMN updated_by UUID REFERENCES users(id);
+
+ALTER TABLE asset_models
+    ADD COLUMN created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN created_by UUID REFERENCES users(id),
+    ADD COLUMN updated_at TIMESTAMPTZ,
+    ADD COLUMN updated_by UUID REFERENCES users(id);
+
+CREATE INDEX idx_asset_models_brand_id ON asset_models(brand_id);
+CREATE INDEX idx_assets_model_id ON assets(model_id);
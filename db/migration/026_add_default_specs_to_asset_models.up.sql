@@ -0,0 +1,3 @@
+-- specs shared by every unit of a model, asset_specs of its assets then only hold per-unit overrides
+ALTER TABLE asset_models
+    ADD COLUMN default_specs JSONB NOT NULL DEFAULT '{}';
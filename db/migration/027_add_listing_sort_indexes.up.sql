@@ -0,0 +1,6 @@
+-- the listings walk these by cursor, a NULL sort value would drop the row from keyset pages
+UPDATE assets SET created_at = purchased_date WHERE created_at IS NULL;
+ALTER TABLE assets ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX idx_assets_created_at_id ON assets(created_at, id);
+CREATE INDEX idx_users_name_id ON users(name, id) WHERE archived_at IS NULL;
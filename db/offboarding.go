@@ -0,0 +1,206 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"storex/models"
+	"time"
+)
+
+func CreateOffboarding(tx *sql.Tx, userID string, lastWorkingDate time.Time, authUserID string) (string, error) {
+	var offboardingID string
+	err := tx.QueryRow(`
+		INSERT INTO user_offboardings (user_id, last_working_date, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, userID, lastWorkingDate, authUserID).Scan(&offboardingID)
+	if err != nil {
+		return "", err
+	}
+	return offboardingID, nil
+}
+
+// HasOpenOffboarding checks whether the user is already being offboarded
+func HasOpenOffboarding(tx *sql.Tx, userID string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM user_offboardings WHERE user_id = $1 AND status = 'open'`, userID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func InsertOffboardingItem(tx *sql.Tx, offboardingID string, assetID string) error {
+	_, err := tx.Exec(`
+		INSERT INTO user_offboarding_items (offboarding_id, asset_id)
+		VALUES ($1, $2)
+	`, offboardingID, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to insert checklist item for asset %s: %w", assetID, err)
+	}
+	return nil
+}
+
+// AddHeldAssetsToOffboarding puts assets the user picked up after the checklist was made on it,
+// reopening items already cleared when the asset went back to the user afterwards
+func AddHeldAssetsToOffboarding(tx *sql.Tx, offboardingID string, userID string) (int64, error) {
+	res, err := tx.Exec(`
+		INSERT INTO user_offboarding_items (offboarding_id, asset_id)
+		SELECT $1, asset_id FROM asset_status
+		WHERE assigned_to_user = $2 AND archived_at IS NULL
+		ON CONFLICT (offboarding_id, asset_id) DO UPDATE
+		SET status = 'pending', remarks = NULL, cleared_at = NULL, cleared_by = NULL
+		WHERE user_offboarding_items.status <> 'pending'
+	`, offboardingID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// LockOffboarding fetches an offboarding for a change, nil when it does not exist
+func LockOffboarding(tx *sql.Tx, offboardingID string) (*models.Offboarding, error) {
+	var ob models.Offboarding
+	err := tx.QueryRow(`
+		SELECT id, user_id, last_working_date, status, created_at
+		FROM user_offboardings
+		WHERE id = $1
+		FOR UPDATE
+	`, offboardingID).Scan(&ob.ID, &ob.UserID, &ob.LastWorkingDate, &ob.Status, &ob.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ob, nil
+}
+
+// GetOffboardingItemStatus returns the checklist status of the asset, empty when it is not on the checklist
+func GetOffboardingItemStatus(tx *sql.Tx, offboardingID string, assetID string) (string, error) {
+	var status string
+	err := tx.QueryRow(`
+		SELECT status FROM user_offboarding_items
+		WHERE offboarding_id = $1 AND asset_id = $2
+	`, offboardingID, assetID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return status, nil
+}
+
+func ClearOffboardingItem(tx *sql.Tx, offboardingID string, assetID string, status string, remarks *string, authUserID string) error {
+	_, err := tx.Exec(`
+		UPDATE user_offboarding_items SET
+			status = $3,
+			remarks = $4,
+			cleared_at = NOW(),
+			cleared_by = $5
+		WHERE offboarding_id = $1 AND asset_id = $2
+	`, offboardingID, assetID, status, remarks, authUserID)
+	return err
+}
+
+func CountPendingOffboardingItems(tx *sql.Tx, offboardingID string) (int, error) {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM user_offboarding_items
+		WHERE offboarding_id = $1 AND status = 'pending'
+	`, offboardingID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func CloseOffboarding(tx *sql.Tx, offboardingID string, status string, authUserID string) error {
+	_, err := tx.Exec(`
+		UPDATE user_offboardings SET status = $2, closed_at = NOW(), closed_by = $3
+		WHERE id = $1
+	`, offboardingID, status, authUserID)
+	return err
+}
+
+const offboardingColumns = `
+	o.id, o.user_id, u.name, u.email, o.last_working_date, o.status,
+	(SELECT COUNT(*) FROM user_offboarding_items i WHERE i.offboarding_id = o.id AND i.status = 'pending'),
+	o.created_at, o.closed_at
+	FROM user_offboardings o
+	JOIN users u ON u.id = o.user_id`
+
+func scanOffboarding(row interface{ Scan(...any) error }, ob *models.Offboarding) error {
+	return row.Scan(
+		&ob.ID, &ob.UserID, &ob.UserName, &ob.UserEmail, &ob.LastWorkingDate, &ob.Status,
+		&ob.PendingItems, &ob.CreatedAt, &ob.ClosedAt,
+	)
+}
+
+// ListOffboardings returns offboardings by last working date, optionally only those in the given statuses
+func ListOffboardings(status []string) ([]models.Offboarding, error) {
+	query := "SELECT" + offboardingColumns + " WHERE 1=1"
+	var args []any
+	if len(status) > 0 {
+		query += " AND o.status::TEXT = ANY($1)"
+		args = append(args, pq.Array(status))
+	}
+	query += " ORDER BY o.last_working_date, o.created_at"
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var offboardings []models.Offboarding
+	for rows.Next() {
+		var ob models.Offboarding
+		if err := scanOffboarding(rows, &ob); err != nil {
+			return nil, err
+		}
+		offboardings = append(offboardings, ob)
+	}
+	return offboardings, rows.Err()
+}
+
+// GetOffboarding returns an offboarding with its checklist, nil when it does not exist
+func GetOffboarding(offboardingID string) (*models.Offboarding, error) {
+	var ob models.Offboarding
+	err := scanOffboarding(DB.QueryRow("SELECT"+offboardingColumns+" WHERE o.id = $1", offboardingID), &ob)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := DB.Query(`
+		SELECT i.asset_id, a.serial_no, b.name, m.name, i.status, i.remarks, i.cleared_at, i.cleared_by
+		FROM user_offboarding_items i
+		JOIN assets a ON a.id = i.asset_id
+		JOIN asset_models m ON m.id = a.model_id
+		JOIN asset_brands b ON b.id = m.brand_id
+		WHERE i.offboarding_id = $1
+		ORDER BY b.name, m.name, a.serial_no
+	`, offboardingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ob.Items = []models.OffboardingItem{}
+	for rows.Next() {
+		var item models.OffboardingItem
+		err := rows.Scan(&item.AssetID, &item.SerialNo, &item.BrandName, &item.ModelName,
+			&item.Status, &item.Remarks, &item.ClearedAt, &item.ClearedBy)
+		if err != nil {
+			return nil, err
+		}
+		ob.Items = append(ob.Items, item)
+	}
+	return &ob, rows.Err()
+}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"storex/models"
+)
+
+func ListReceiptTemplates() ([]models.ReceiptTemplate, error) {
+	rows, err := DB.Query(`
+		SELECT kind, organisation, title, intro, footer, updated_at
+		FROM receipt_templates
+		ORDER BY kind
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []models.ReceiptTemplate
+	for rows.Next() {
+		var t models.ReceiptTemplate
+		if err := rows.Scan(&t.Kind, &t.Organisation, &t.Title, &t.Intro, &t.Footer, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// GetReceiptTemplate returns the template of a receipt kind, nil when there is none
+func GetReceiptTemplate(kind string) (*models.ReceiptTemplate, error) {
+	var t models.ReceiptTemplate
+	err := DB.QueryRow(`
+		SELECT kind, organisation, title, intro, footer, updated_at
+		FROM receipt_templates
+		WHERE kind::TEXT = $1
+	`, kind).Scan(&t.Kind, &t.Organisation, &t.Title, &t.Intro, &t.Footer, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func UpdateReceiptTemplate(t *models.ReceiptTemplate, authUserID string) error {
+	_, err := DB.Exec(`
+		UPDATE receipt_templates SET
+			organisation = $2,
+			title = $3,
+			intro = $4,
+			footer = $5,
+			updated_at = NOW(),
+			updated_by = $6
+		WHERE kind::TEXT = $1
+	`, t.Kind, t.Organisation, t.Title, t.Intro, t.Footer, authUserID)
+	return err
+}
+
+const receiptAssetColumns = `
+	a.id, a.serial_no, b.name, m.name, m.asset_type, ` + effectiveSpecsSQL + `, s.created_at, s.archived_at`
+
+const receiptJoins = `
+	FROM asset_status s
+	JOIN assets a ON a.id = s.asset_id
+	JOIN asset_models m ON m.id = a.model_id
+	JOIN asset_brands b ON b.id = m.brand_id
+	JOIN asset_specs sp ON sp.id = a.specs_id
+	JOIN users u ON u.id = s.assigned_to_user`
+
+func scanReceiptAsset(dest []any, asset *models.ReceiptAsset, specs *[]byte) []any {
+	return append(dest,
+		&asset.AssetID, &asset.SerialNo, &asset.BrandName, &asset.ModelName, &asset.AssetType,
+		specs, &asset.AssignedAt, &asset.ReturnedAt,
+	)
+}
+
+// GetStatusReceipt loads an assignment row of asset_status for its handover or return receipt,
+// nil when the row does not exist or is not an assignment
+func GetStatusReceipt(statusID string) (*models.Receipt, error) {
+	query := `
+		SELECT u.name, u.email, s.acknowledged_at, s.acknowledgement_signature,
+			COALESCE(s.remarks, s.transfer_reason), n.status, n.remarks,` + receiptAssetColumns +
+		receiptJoins + `
+		LEFT JOIN LATERAL (
+			SELECT nx.status::TEXT AS status, nx.remarks
+			FROM asset_status nx
+			WHERE nx.asset_id = s.asset_id AND nx.id <> s.id AND nx.created_at >= s.archived_at
+			ORDER BY nx.created_at
+			LIMIT 1
+		) n ON true
+		WHERE s.id = $1 AND s.status = 'assigned'
+	`
+
+	var receipt models.Receipt
+	var asset models.ReceiptAsset
+	var handoverRemarks, nextStatus, nextRemarks *string
+	var specs []byte
+	dest := []any{
+		&receipt.EmployeeName, &receipt.EmployeeEmail, &receipt.AcknowledgedAt, &receipt.Signature,
+		&handoverRemarks, &nextStatus, &nextRemarks,
+	}
+	err := DB.QueryRow(query, statusID).Scan(scanReceiptAsset(dest, &asset, &specs)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(specs, &asset.Specs); err != nil {
+		return nil, fmt.Errorf("corrupt specs of asset %s: %w", asset.AssetID, err)
+	}
+
+	// the handover carries its own remarks, a return is described by the status the asset went into
+	asset.Condition = handoverRemarks
+	if asset.ReturnedAt != nil && nextStatus != nil {
+		condition := "returned as " + *nextStatus
+		if nextRemarks != nil {
+			condition += ": " + *nextRemarks
+		}
+		asset.Condition = &condition
+	}
+
+	receipt.Number = statusID
+	receipt.Assets = []models.ReceiptAsset{asset}
+	return &receipt, nil
+}
+
+// GetHeldAssetsReceipt lists every asset currently assigned to the user for a statement, nil when the user does not exist
+func GetHeldAssetsReceipt(userID string) (*models.Receipt, error) {
+	receipt := models.Receipt{Number: userID, Assets: []models.ReceiptAsset{}}
+	err := DB.QueryRow(`SELECT name, email FROM users WHERE id = $1`, userID).Scan(&receipt.EmployeeName, &receipt.EmployeeEmail)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := DB.Query(`
+		SELECT COALESCE(s.remarks, s.transfer_reason),`+receiptAssetColumns+receiptJoins+`
+		WHERE s.assigned_to_user = $1 AND s.status = 'assigned' AND s.archived_at IS NULL
+		ORDER BY s.created_at
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var asset models.ReceiptAsset
+		var specs []byte
+		if err := rows.Scan(scanReceiptAsset([]any{&asset.Condition}, &asset, &specs)...); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(specs, &asset.Specs); err != nil {
+			return nil, fmt.Errorf("corrupt specs of asset %s: %w", asset.AssetID, err)
+		}
+		receipt.Assets = append(receipt.Assets, asset)
+	}
+	return &receipt, rows.Err()
+}
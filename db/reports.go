@@ -0,0 +1,222 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"slices"
+	"storex/models"
+)
+
+func CreateAssetReport(tx *sql.Tx, req *models.CreateAssetReportRequest, userID string) (string, error) {
+	var reportID string
+	err := tx.QueryRow(`
+		INSERT INTO asset_reports (asset_id, reported_by, report_type, description, return_date)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, req.AssetID, userID, req.Type, req.Description, req.ReturnDate).Scan(&reportID)
+	if err != nil {
+		return "", err
+	}
+	return reportID, nil
+}
+
+func InsertAssetReportPhoto(tx *sql.Tx, reportID string, photo *models.AssetReportPhotoUpload) error {
+	_, err := tx.Exec(`
+		INSERT INTO asset_report_photos (report_id, file_name, content_type, data)
+		VALUES ($1, $2, $3, $4)
+	`, reportID, photo.FileName, photo.ContentType, photo.Data)
+	if err != nil {
+		return fmt.Errorf("failed to store photo %s: %w", photo.FileName, err)
+	}
+	return nil
+}
+
+// HasOpenAssetReport checks whether the asset already waits on a report in the queue
+func HasOpenAssetReport(tx *sql.Tx, assetID string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM asset_reports WHERE asset_id = $1 AND status = 'open'`, assetID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// LockAssetReport fetches a report for resolution, nil when it does not exist
+func LockAssetReport(tx *sql.Tx, reportID string) (*models.AssetReport, error) {
+	var report models.AssetReport
+	err := tx.QueryRow(`
+		SELECT id, asset_id, reported_by, report_type, description, status, created_at
+		FROM asset_reports
+		WHERE id = $1
+		FOR UPDATE
+	`, reportID).Scan(&report.ID, &report.AssetID, &report.ReportedBy, &report.Type, &report.Description, &report.Status, &report.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+func ResolveAssetReport(tx *sql.Tx, reportID string, status string, remarks *string, authUserID string) error {
+	_, err := tx.Exec(`
+		UPDATE asset_reports SET
+			status = $2,
+			resolution_remarks = $3,
+			resolved_by = $4,
+			resolved_at = NOW()
+		WHERE id = $1
+	`, reportID, status, remarks, authUserID)
+	return err
+}
+
+const assetReportColumns = `
+	r.id, r.asset_id, a.serial_no, m.name, b.name,
+	r.reported_by, u.name, r.report_type, r.description, r.return_date,
+	r.status, r.resolution_remarks, r.resolved_by, r.resolved_at, r.created_at`
+
+const assetReportJoins = `
+	FROM asset_reports r
+	JOIN assets a ON a.id = r.asset_id
+	JOIN asset_models m ON m.id = a.model_id
+	JOIN asset_brands b ON b.id = m.brand_id
+	JOIN users u ON u.id = r.reported_by`
+
+// scanAssetReport scans assetReportColumns, then any extra columns the query selects after them
+func scanAssetReport(row interface{ Scan(...any) error }, report *models.AssetReport, extra ...any) error {
+	dest := []any{
+		&report.ID, &report.AssetID, &report.SerialNo, &report.ModelName, &report.BrandName,
+		&report.ReportedBy, &report.ReporterName, &report.Type, &report.Description, &report.ReturnDate,
+		&report.Status, &report.ResolutionRemarks, &report.ResolvedBy, &report.ResolvedAt, &report.CreatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// ReportSortFields are what the report listing can be sorted on
+var ReportSortFields = []models.SortField{
+	{Name: "created_at", Column: "r.created_at", Type: "TIMESTAMPTZ"},
+	{Name: "type", Column: "r.report_type::TEXT", Type: "TEXT"},
+	{Name: "status", Column: "r.status::TEXT", Type: "TEXT"},
+}
+
+// oldest first so the queue is worked in order
+var defaultReportSort = []models.SortKey{{SortField: ReportSortFields[0]}}
+
+// ListAssetReports returns a page of reports. Walking a cursor backward the rows still come in listing order.
+func ListAssetReports(params *models.AssetReportFilterParams) ([]models.AssetReport, error) {
+	where, args, argIndex := assetReportFilterSQL(params, 1)
+	listing, listArgs, _ := buildListingSQL(&params.ListParams, defaultReportSort, "r.id", argIndex)
+	args = append(args, listArgs...)
+
+	query := "SELECT" + assetReportColumns + ", " + listing.cursor + assetReportJoins + " WHERE 1=1" + where + listing.cond + listing.order
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []models.AssetReport
+	for rows.Next() {
+		var report models.AssetReport
+		if err := scanAssetReport(rows, &report, pq.Array(&report.Cursor)); err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if params.Backward {
+		slices.Reverse(reports)
+	}
+	return reports, nil
+}
+
+// CountAssetReports counts the reports matching the listing filters
+func CountAssetReports(params *models.AssetReportFilterParams) (int, error) {
+	where, args, _ := assetReportFilterSQL(params, 1)
+
+	var total int
+	err := DB.QueryRow("SELECT COUNT(*) FROM asset_reports r WHERE 1=1"+where, args...).Scan(&total)
+	return total, err
+}
+
+// assetReportFilterSQL builds the WHERE conditions of the report listing, on asset_reports r
+func assetReportFilterSQL(params *models.AssetReportFilterParams, argIndex int) (string, []any, int) {
+	var query string
+	var args []any
+
+	if params.ReportedBy != "" {
+		query += fmt.Sprintf(" AND r.reported_by = $%d", argIndex)
+		args = append(args, params.ReportedBy)
+		argIndex++
+	}
+
+	if len(params.Types) > 0 {
+		query += fmt.Sprintf(" AND r.report_type::TEXT = ANY($%d)", argIndex)
+		args = append(args, pq.Array(params.Types))
+		argIndex++
+	}
+
+	if len(params.Status) > 0 {
+		query += fmt.Sprintf(" AND r.status::TEXT = ANY($%d)", argIndex)
+		args = append(args, pq.Array(params.Status))
+		argIndex++
+	}
+
+	return query, args, argIndex
+}
+
+// GetAssetReport returns a report with its photo metadata, nil when it does not exist
+func GetAssetReport(reportID string) (*models.AssetReport, error) {
+	var report models.AssetReport
+	err := scanAssetReport(DB.QueryRow("SELECT"+assetReportColumns+assetReportJoins+" WHERE r.id = $1", reportID), &report)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := DB.Query(`
+		SELECT id, file_name, content_type, octet_length(data), created_at
+		FROM asset_report_photos
+		WHERE report_id = $1
+		ORDER BY created_at
+	`, reportID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var photo models.AssetReportPhoto
+		if err := rows.Scan(&photo.ID, &photo.FileName, &photo.ContentType, &photo.Size, &photo.CreatedAt); err != nil {
+			return nil, err
+		}
+		report.Photos = append(report.Photos, photo)
+	}
+	return &report, rows.Err()
+}
+
+// GetAssetReportPhoto returns the stored photo, nil when it does not belong to the report
+func GetAssetReportPhoto(reportID string, photoID string) (*models.AssetReportPhotoUpload, error) {
+	var photo models.AssetReportPhotoUpload
+	err := DB.QueryRow(`
+		SELECT file_name, content_type, data
+		FROM asset_report_photos
+		WHERE id = $1 AND report_id = $2
+	`, photoID, reportID).Scan(&photo.FileName, &photo.ContentType, &photo.Data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &photo, nil
+}
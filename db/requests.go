@@ -0,0 +1,197 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"slices"
+	"storex/models"
+)
+
+func CreateAssetRequest(tx *sql.Tx, req *models.CreateAssetRequestRequest, userID string) (string, error) {
+	var requestID string
+	err := tx.QueryRow(`
+		INSERT INTO asset_requests (requested_by, asset_type, description)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, userID, req.AssetType, req.Description).Scan(&requestID)
+	if err != nil {
+		return "", err
+	}
+	return requestID, nil
+}
+
+// LockAssetRequest fetches a request for a status change, nil when it does not exist
+func LockAssetRequest(tx *sql.Tx, requestID string) (*models.AssetRequest, error) {
+	var req models.AssetRequest
+	err := tx.QueryRow(`
+		SELECT id, requested_by, asset_type, description, status, created_at
+		FROM asset_requests
+		WHERE id = $1
+		FOR UPDATE
+	`, requestID).Scan(&req.ID, &req.RequestedBy, &req.AssetType, &req.Description, &req.Status, &req.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &req, nil
+}
+
+func UpdateAssetRequestStatus(tx *sql.Tx, requestID string, status string, fulfilledAssetID *string, authUserID string) error {
+	_, err := tx.Exec(`
+		UPDATE asset_requests SET
+			status = $2,
+			fulfilled_asset_id = COALESCE($3, fulfilled_asset_id),
+			updated_at = NOW(),
+			updated_by = $4
+		WHERE id = $1
+	`, requestID, status, fulfilledAssetID, authUserID)
+	return err
+}
+
+// InsertAssetRequestEvent records a status change or, with a nil status, a comment
+func InsertAssetRequestEvent(tx *sql.Tx, requestID string, actorID string, status *string, comment *string) error {
+	_, err := tx.Exec(`
+		INSERT INTO asset_request_events (request_id, actor_id, status, comment)
+		VALUES ($1, $2, $3, $4)
+	`, requestID, actorID, status, comment)
+	if err != nil {
+		return fmt.Errorf("failed to insert request event: %w", err)
+	}
+	return nil
+}
+
+// RequestSortFields are what the request listing can be sorted on
+var RequestSortFields = []models.SortField{
+	{Name: "created_at", Column: "r.created_at", Type: "TIMESTAMPTZ"},
+	{Name: "updated_at", Column: "COALESCE(r.updated_at, r.created_at)", Type: "TIMESTAMPTZ"},
+	{Name: "status", Column: "r.status::TEXT", Type: "TEXT"},
+}
+
+// the newest requests come first unless the caller sorts otherwise
+var defaultRequestSort = []models.SortKey{{SortField: RequestSortFields[0], Desc: true}}
+
+// ListAssetRequests returns a page of requests. Walking a cursor backward the rows still come in listing order.
+func ListAssetRequests(params *models.AssetRequestFilterParams) ([]models.AssetRequest, error) {
+	where, args, argIndex := assetRequestFilterSQL(params, 1)
+	listing, listArgs, _ := buildListingSQL(&params.ListParams, defaultRequestSort, "r.id", argIndex)
+	args = append(args, listArgs...)
+
+	query := `
+		SELECT
+			r.id, r.requested_by, u.name, u.email, r.asset_type, r.description,
+			r.status, r.fulfilled_asset_id, r.created_at, r.updated_at,
+			` + listing.cursor + `
+		FROM asset_requests r
+		JOIN users u ON u.id = r.requested_by
+		WHERE 1=1
+	` + where + listing.cond + listing.order
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []models.AssetRequest
+	for rows.Next() {
+		var req models.AssetRequest
+		err := rows.Scan(
+			&req.ID, &req.RequestedBy, &req.RequesterName, &req.RequesterEmail, &req.AssetType, &req.Description,
+			&req.Status, &req.FulfilledAssetID, &req.CreatedAt, &req.UpdatedAt, pq.Array(&req.Cursor),
+		)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if params.Backward {
+		slices.Reverse(requests)
+	}
+	return requests, nil
+}
+
+// CountAssetRequests counts the requests matching the listing filters
+func CountAssetRequests(params *models.AssetRequestFilterParams) (int, error) {
+	where, args, _ := assetRequestFilterSQL(params, 1)
+
+	var total int
+	err := DB.QueryRow("SELECT COUNT(*) FROM asset_requests r WHERE 1=1"+where, args...).Scan(&total)
+	return total, err
+}
+
+// assetRequestFilterSQL builds the WHERE conditions of the request listing, on asset_requests r
+func assetRequestFilterSQL(params *models.AssetRequestFilterParams, argIndex int) (string, []any, int) {
+	var query string
+	var args []any
+
+	if params.RequestedBy != "" {
+		query += fmt.Sprintf(" AND r.requested_by = $%d", argIndex)
+		args = append(args, params.RequestedBy)
+		argIndex++
+	}
+
+	if len(params.Status) > 0 {
+		query += fmt.Sprintf(" AND r.status::TEXT = ANY($%d)", argIndex)
+		args = append(args, pq.Array(params.Status))
+		argIndex++
+	}
+
+	if len(params.AssetTypes) > 0 {
+		query += fmt.Sprintf(" AND r.asset_type = ANY($%d)", argIndex)
+		args = append(args, pq.Array(params.AssetTypes))
+		argIndex++
+	}
+
+	return query, args, argIndex
+}
+
+// GetAssetRequest returns a request with its history, nil when it does not exist
+func GetAssetRequest(requestID string) (*models.AssetRequest, error) {
+	var req models.AssetRequest
+	err := DB.QueryRow(`
+		SELECT
+			r.id, r.requested_by, u.name, u.email, r.asset_type, r.description,
+			r.status, r.fulfilled_asset_id, r.created_at, r.updated_at
+		FROM asset_requests r
+		JOIN users u ON u.id = r.requested_by
+		WHERE r.id = $1
+	`, requestID).Scan(
+		&req.ID, &req.RequestedBy, &req.RequesterName, &req.RequesterEmail, &req.AssetType, &req.Description,
+		&req.Status, &req.FulfilledAssetID, &req.CreatedAt, &req.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := DB.Query(`
+		SELECT e.actor_id, u.name, e.status, e.comment, e.created_at
+		FROM asset_request_events e
+		JOIN users u ON u.id = e.actor_id
+		WHERE e.request_id = $1
+		ORDER BY e.created_at
+	`, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.AssetRequestEvent
+		if err := rows.Scan(&event.ActorID, &event.ActorName, &event.Status, &event.Comment, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		req.History = append(req.History, event)
+	}
+	return &req, rows.Err()
+}
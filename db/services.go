@@ -0,0 +1,196 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"storex/models"
+)
+
+func CreateService(service *models.Service, authUserID string) (string, error) {
+	var serviceID string
+
+	err := DB.QueryRow(`
+		INSERT INTO services(name, email, phone, location, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, service.Name, service.Email, service.Phone, service.Location, authUserID).Scan(&serviceID)
+	if err != nil {
+		return "", err
+	}
+	return serviceID, nil
+}
+
+func IsServiceExistByID(tx *sql.Tx, serviceID string) error {
+	query := `SELECT id FROM services WHERE id = $1 AND archived_at IS NULL`
+	return tx.QueryRow(query, serviceID).Scan(&serviceID)
+}
+
+// IsServiceActive checks that the vendor exists and is not archived
+func IsServiceActive(serviceID string) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM services WHERE id = $1 AND archived_at IS NULL)`, serviceID).Scan(&exists)
+	return exists, err
+}
+
+func ListServices(filters *models.ServiceFilterParams) ([]models.ListServicesResponse, error) {
+	query := `
+	SELECT
+		sv.id,
+		sv.name,
+		sv.email,
+		sv.phone,
+		sv.location,
+		sv.created_at,
+		COUNT(s.id) AS active_asset_count
+	FROM services sv
+	LEFT JOIN asset_status s ON s.sent_to_service = sv.id AND s.status = 'service' AND s.archived_at IS NULL
+	WHERE sv.archived_at IS NULL
+`
+
+	var args []interface{}
+	argIndex := 1
+
+	if filters.Search != "" {
+		query += fmt.Sprintf(" AND (sv.name ILIKE $%d OR sv.email ILIKE $%d OR sv.phone ILIKE $%d OR sv.location ILIKE $%d)", argIndex, argIndex+1, argIndex+2, argIndex+3)
+		args = append(args, "%"+filters.Search+"%", "%"+filters.Search+"%", "%"+filters.Search+"%", "%"+filters.Search+"%")
+		argIndex += 4
+	}
+
+	query += fmt.Sprintf(`
+	GROUP BY sv.id
+	ORDER BY sv.name
+	LIMIT $%d OFFSET $%d
+	`, argIndex, argIndex+1)
+
+	args = append(args, filters.Limit, filters.Offset)
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []models.ListServicesResponse
+	for rows.Next() {
+		var sv models.ListServicesResponse
+		err := rows.Scan(&sv.ID, &sv.Name, &sv.Email, &sv.Phone, &sv.Location, &sv.CreatedAt, &sv.ActiveAssetCount)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, sv)
+	}
+	return services, nil
+}
+
+func UpdateService(authUserID string, serviceID string, req *models.UpdateServiceRequest) (int64, error) {
+	query := `
+		UPDATE services SET
+			name = COALESCE($1, name),
+			email = COALESCE($2, email),
+			phone = COALESCE($3, phone),
+			location = COALESCE($4, location),
+			updated_at = CURRENT_TIMESTAMP,
+			updated_by = $5
+		WHERE id = $6 AND archived_at IS NULL
+	`
+
+	res, err := DB.Exec(query,
+		req.Name,
+		req.Email,
+		req.Phone,
+		req.Location,
+		authUserID,
+		serviceID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func NumberOfAssetsInService(tx *sql.Tx, serviceID string) (int, error) {
+	count := 0
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM asset_status
+		WHERE sent_to_service = $1 AND status = 'service' AND archived_at IS NULL
+	`, serviceID).Scan(&count)
+
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func SoftDeleteService(tx *sql.Tx, serviceID string, authUserID string) (int64, error) {
+	res, err := tx.Exec(`
+		UPDATE services SET archived_at = NOW(), archived_by = $2 WHERE id = $1 AND archived_at IS NULL
+	`, serviceID, authUserID)
+
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func GetServiceStats(serviceID string) (models.ServiceStats, error) {
+	query := `
+		SELECT
+			sv.id, sv.name, sv.email, sv.phone, sv.location,
+			COUNT(s.id) FILTER (WHERE s.archived_at IS NULL) AS active_asset_count,
+			COUNT(s.id) FILTER (WHERE s.archived_at IS NOT NULL) AS completed_job_count,
+			EXTRACT(EPOCH FROM AVG(s.archived_at - s.created_at) FILTER (WHERE s.archived_at IS NOT NULL)) / 3600 AS avg_turnaround_hours,
+			COUNT(s.id) FILTER (WHERE s.repair_outcome = 'repaired') AS repaired_count,
+			COUNT(s.id) FILTER (WHERE s.repair_outcome = 'unrepairable') AS unrepairable_count
+		FROM services sv
+		LEFT JOIN asset_status s ON s.sent_to_service = sv.id AND s.status = 'service'
+		WHERE sv.id = $1 AND sv.archived_at IS NULL
+		GROUP BY sv.id
+	`
+
+	var stats models.ServiceStats
+	err := DB.QueryRow(query, serviceID).Scan(
+		&stats.ID,
+		&stats.Name,
+		&stats.Email,
+		&stats.Phone,
+		&stats.Location,
+		&stats.ActiveAssetCount,
+		&stats.CompletedJobCount,
+		&stats.AvgTurnaroundHours,
+		&stats.RepairedCount,
+		&stats.UnrepairableCount,
+	)
+	return stats, err
+}
+
+func FetchServiceJobs(serviceID string) ([]models.ServiceJob, error) {
+	query := `
+		SELECT
+			s.id, a.id, a.serial_no, m.name, b.name, m.asset_type,
+			s.remarks, s.repair_outcome, s.created_at, s.archived_at
+		FROM asset_status s
+		JOIN assets a ON a.id = s.asset_id
+		JOIN asset_models m ON m.id = a.model_id
+		JOIN asset_brands b ON b.id = m.brand_id
+		WHERE s.sent_to_service = $1 AND s.status = 'service'
+		ORDER BY s.created_at DESC
+	`
+
+	rows, err := DB.Query(query, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []models.ServiceJob{}
+	for rows.Next() {
+		var job models.ServiceJob
+		err := rows.Scan(&job.StatusID, &job.AssetID, &job.SerialNo, &job.ModelName, &job.BrandName, &job.AssetType,
+			&job.Remarks, &job.RepairOutcome, &job.SentAt, &job.ReturnedAt)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
@@ -4,10 +4,26 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/lib/pq"
+	"slices"
 	"storex/models"
 )
 
+// UserSortFields are what the user listing can be sorted on
+var UserSortFields = []models.SortField{
+	{Name: "name", Column: "u.name", Type: "TEXT"},
+	{Name: "email", Column: "u.email", Type: "TEXT"},
+	{Name: "user_type", Column: "u.user_type::TEXT", Type: "TEXT"},
+	{Name: "created_at", Column: "COALESCE(u.created_at, '-infinity')", Type: "TIMESTAMPTZ"},
+}
+
+var defaultUserSort = []models.SortKey{{SortField: UserSortFields[0]}}
+
+// ListUsers returns a page of users. Walking a cursor backward the rows still come in listing order.
 func ListUsers(filters *models.UserFilterParams) ([]models.ListUsersResponse, error) {
+	where, args, argIndex := userFilterSQL(filters, 1)
+	listing, listArgs, _ := buildListingSQL(&filters.ListParams, defaultUserSort, "u.id", argIndex)
+	args = append(args, listArgs...)
+
 	query := `
     SELECT
         u.id,
@@ -20,15 +36,73 @@ func ListUsers(filters *models.UserFilterParams) ([]models.ListUsersResponse, er
             WHERE s.status = 'assigned'
             AND s.assigned_to_user = u.id
             AND s.archived_at IS NULL
-        ) AS assigned_asset_count
+            AND s.acknowledged_at IS NOT NULL
+        ) AS assigned_asset_count,
+        ` + listing.cursor + `
     FROM users u
     LEFT JOIN user_roles ur ON ur.user_id = u.id
     LEFT JOIN asset_status s ON s.assigned_to_user = u.id AND s.archived_at IS NULL
     WHERE u.archived_at IS NULL
-`
+` + where + listing.cond + `
+	GROUP BY u.id` + listing.order
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.ListUsersResponse
+	for rows.Next() {
+		var u models.ListUsersResponse
+		var roles []sql.NullString
+		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Phone, &u.UserType, pq.Array(&roles), &u.AssignedAssetCount,
+			pq.Array(&u.Cursor))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range roles {
+			if r.Valid {
+				u.Roles = append(u.Roles, r.String)
+			}
+		}
+
+		users = append(users, u)
+	}
+
+	if filters.Backward {
+		slices.Reverse(users)
+	}
+	return users, nil
+}
+
+// CountUsers counts the active users matching the listing filters
+func CountUsers(filters *models.UserFilterParams) (int, error) {
+	where, args, _ := userFilterSQL(filters, 1)
+
+	var total int
+	err := DB.QueryRow("SELECT COUNT(*) FROM users u WHERE u.archived_at IS NULL"+where, args...).Scan(&total)
+	return total, err
+}
+
+// UserFacetFields are what the user listing can count its users by
+var UserFacetFields = []models.FacetField{
+	{Name: "user_type", Column: "u.user_type::TEXT"},
+	{Name: "role", Column: "ur.role::TEXT", Join: "JOIN user_roles ur ON ur.user_id = u.id"},
+}
+
+// UserFacets counts the users matching the listing filters per value of each facet,
+// a user with several roles counts for each of them
+func UserFacets(filters *models.UserFilterParams, facets []models.FacetField) (map[string][]models.FacetCount, error) {
+	where, args, _ := userFilterSQL(filters, 1)
+	return facetCounts(facets, "FROM users u", "WHERE u.archived_at IS NULL"+where, "u.id", args)
+}
 
-	var args []interface{}
-	argIndex := 1
+// userFilterSQL builds the WHERE conditions of the user listing, on users u
+func userFilterSQL(filters *models.UserFilterParams, argIndex int) (string, []any, int) {
+	var query string
+	var args []any
 
 	if filters.Search != "" {
 		query += fmt.Sprintf(" AND (u.name ILIKE $%d OR u.email ILIKE $%d OR u.phone ILIKE $%d)", argIndex, argIndex+1, argIndex+2)
@@ -75,43 +149,7 @@ func ListUsers(filters *models.UserFilterParams) ([]models.ListUsersResponse, er
 	//	argIndex++
 	//}
 
-	query += fmt.Sprintf(`
-	GROUP BY u.id
-	ORDER BY u.name
-	LIMIT $%d OFFSET $%d
-	`, argIndex, argIndex+1)
-
-	args = append(args, filters.Limit, filters.Offset)
-	//query += `
-	//GROUP BY u.id
-	//ORDER BY u.name
-	//LIMIT $` + fmt.Sprint(argIndex) + ` OFFSET $` + fmt.Sprint(argIndex+1)
-	//args = append(args, filters.Limit, filters.Offset)
-
-	rows, err := DB.Query(query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var users []models.ListUsersResponse
-	for rows.Next() {
-		var u models.ListUsersResponse
-		var roles []sql.NullString
-		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Phone, &u.UserType, pq.Array(&roles), &u.AssignedAssetCount)
-		if err != nil {
-			return nil, err
-		}
-
-		for _, r := range roles {
-			if r.Valid {
-				u.Roles = append(u.Roles, r.String)
-			}
-		}
-
-		users = append(users, u)
-	}
-	return users, nil
+	return query, args, argIndex
 }
 
 func CreateProtectedUser(tx *sql.Tx, user *models.User) (string, error) {
@@ -151,10 +189,10 @@ func UpdateUser(authUserID string, userID string, req *models.UpdateUserRequest)
 	return nil
 }
 
-func SoftDeleteUser(tx *sql.Tx, userID string) error {
+func SoftDeleteUser(tx *sql.Tx, userID string, authUserID string) error {
 	_, err := tx.Exec(`
-		UPDATE users SET archived_at = NOW() WHERE id = $1 AND archived_at IS NULL
-	`, userID)
+		UPDATE users SET archived_at = NOW(), archived_by = $2 WHERE id = $1 AND archived_at IS NULL
+	`, userID, authUserID)
 
 	if err != nil {
 		return err
@@ -171,7 +209,14 @@ func GetUserDetailsByUserID(userID string) (models.UserDetails, error) {
 				WHERE ast.status = 'assigned'
 				AND ast.assigned_to_user = u.id
 				AND ast.archived_at IS NULL
-			) AS assigned_asset_count
+				AND ast.acknowledged_at IS NOT NULL
+			) AS assigned_asset_count,
+			COUNT(DISTINCT ast.id) FILTER (
+				WHERE ast.status = 'assigned'
+				AND ast.assigned_to_user = u.id
+				AND ast.archived_at IS NULL
+				AND ast.acknowledged_at IS NULL
+			) AS pending_handovers
 		FROM users u
 		LEFT JOIN user_roles ur ON ur.user_id = u.id
 		LEFT JOIN asset_status ast ON ast.assigned_to_user = u.id
@@ -181,7 +226,7 @@ func GetUserDetailsByUserID(userID string) (models.UserDetails, error) {
 
 	var user models.UserDetails
 	var roles []sql.NullString
-	err := DB.QueryRow(query, userID).Scan(&user.ID, &user.Name, &user.Email, &user.UserType, pq.Array(&roles), &user.AssignedAssetCount)
+	err := DB.QueryRow(query, userID).Scan(&user.ID, &user.Name, &user.Email, &user.UserType, pq.Array(&roles), &user.AssignedAssetCount, &user.PendingHandovers)
 	if err != nil {
 		return user, err
 	}
@@ -193,3 +238,26 @@ func GetUserDetailsByUserID(userID string) (models.UserDetails, error) {
 	}
 	return user, nil
 }
+
+func ListUserEmailsByRoles(roles []string) ([]string, error) {
+	rows, err := DB.Query(`
+		SELECT DISTINCT u.email
+		FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id
+		WHERE ur.role = ANY($1) AND u.archived_at IS NULL
+	`, pq.Array(roles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, nil
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"storex/models"
+	"time"
+)
+
+// ListWarrantyExpiring returns active assets whose warranty ends between from and to, soonest first
+func ListWarrantyExpiring(from time.Time, to time.Time) ([]models.WarrantyAsset, error) {
+	query := `
+		SELECT a.id, a.serial_no, b.name, m.name, m.asset_type, a.warranty_exp_date, u.name
+		FROM assets a
+		JOIN asset_models m ON a.model_id = m.id
+		JOIN asset_brands b ON m.brand_id = b.id
+		LEFT JOIN asset_status s ON s.asset_id = a.id AND s.archived_at IS NULL
+		LEFT JOIN users u ON u.id = s.assigned_to_user
+		WHERE a.archived_at IS NULL
+		AND a.warranty_exp_date >= $1 AND a.warranty_exp_date < $2
+		ORDER BY a.warranty_exp_date
+	`
+
+	rows, err := DB.Query(query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assets []models.WarrantyAsset
+	for rows.Next() {
+		var a models.WarrantyAsset
+		err := rows.Scan(&a.AssetID, &a.SerialNo, &a.BrandName, &a.ModelName, &a.AssetType, &a.WarrantyExpDate, &a.AssignedUserName)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, a)
+	}
+	return assets, nil
+}
+
+// ClaimWarrantyReminder records a reminder before it is sent, it returns false when it was already sent
+func ClaimWarrantyReminder(assetID string, windowDays int, expDate time.Time) (bool, error) {
+	res, err := DB.Exec(`
+		INSERT INTO warranty_reminders (asset_id, window_days, warranty_exp_date)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, assetID, windowDays, expDate)
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return claimed == 1, nil
+}
+
+// ReleaseWarrantyReminder drops a claim whose notification could not be delivered so it is retried
+func ReleaseWarrantyReminder(assetID string, windowDays int, expDate time.Time) error {
+	_, err := DB.Exec(`
+		DELETE FROM warranty_reminders
+		WHERE asset_id = $1 AND window_days = $2 AND warranty_exp_date = $3
+	`, assetID, windowDays, expDate)
+	return err
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strings"
+)
+
+func ListAssetTypes(w http.ResponseWriter, r *http.Request) {
+	defs, err := db.ListAssetTypes()
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list asset types", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(defs)
+}
+
+func GetAssetType(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	def, err := db.GetAssetType(name)
+	if err != nil {
+		if errors.Is(err, db.ErrUnknownAssetType) {
+			http.Error(w, "asset type not found", http.StatusNotFound)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset type", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(def)
+}
+
+func CreateAssetType(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAssetTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !utils.IsValidIdentifier(req.Name) {
+		http.Error(w, "name must be lowercase letters, digits and underscores", http.StatusBadRequest)
+		return
+	}
+
+	if err := utils.ValidateSpecFields(req.Fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := utils.ValidateDepreciationPolicy(req.DepreciationPolicy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	created, err := db.CreateAssetType(&req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to create asset type", http.StatusInternalServerError)
+		return
+	}
+
+	if created == 0 {
+		http.Error(w, "asset type already exists", http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":    "Asset type created successfully",
+		"asset_type": req.Name,
+	})
+}
+
+func UpdateAssetType(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	var req models.UpdateAssetTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := utils.ValidateSpecFields(req.Fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := utils.ValidateDepreciationPolicy(req.DepreciationPolicy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	def, err := db.GetAssetType(name)
+	if err != nil {
+		if errors.Is(err, db.ErrUnknownAssetType) {
+			http.Error(w, "asset type not found", http.StatusNotFound)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset type", http.StatusInternalServerError)
+		return
+	}
+	// fields of built-in types are part of the schema and cannot be changed here
+	if def.BuiltIn && req.Fields != nil {
+		http.Error(w, "fields of built-in asset types cannot be changed", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	// specs already stored have to fit the new fields, filters cast them by field type
+	if req.Fields != nil {
+		mismatches, err := db.SpecsNotMatching(tx, name, req.Fields, 10)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to check stored specs", http.StatusInternalServerError)
+			return
+		}
+		if len(mismatches) > 0 {
+			http.Error(w, "stored specs do not fit the new fields: "+strings.Join(mismatches, "; "), http.StatusConflict)
+			return
+		}
+	}
+
+	authUserID := middleware.GetUserID(r)
+	updated, err := db.UpdateAssetType(tx, name, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update asset type", http.StatusInternalServerError)
+		return
+	}
+
+	// archived since it was looked up
+	if updated == 0 {
+		http.Error(w, "asset type not found or built-in", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Asset type updated successfully",
+	})
+}
+
+func DeleteAssetType(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	modelCount, err := db.NumberOfModelsByAssetType(tx, name)
+	if err != nil {
+		http.Error(w, "failed to check asset type usage", http.StatusInternalServerError)
+		return
+	}
+	if modelCount > 0 {
+		http.Error(w, "asset type cannot be deleted while models use it", http.StatusBadRequest)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	archived, err := db.ArchiveAssetType(tx, name, authUserID)
+	if err != nil {
+		http.Error(w, "failed to archive asset type", http.StatusInternalServerError)
+		return
+	}
+	if archived == 0 {
+		http.Error(w, "asset type not found or built-in", http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte("asset type deleted successfully"))
+}
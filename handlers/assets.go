@@ -2,14 +2,18 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"github.com/go-chi/chi/v5"
+	"io"
 	"log"
 	"net/http"
+	"slices"
 	"storex/db"
 	"storex/middleware"
 	"storex/models"
-	"strconv"
+	"storex/utils"
 	"strings"
+	"time"
 )
 
 func CreateAsset(w http.ResponseWriter, r *http.Request) {
@@ -24,6 +28,18 @@ func CreateAsset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := utils.ValidateAssetCost(req.PurchaseCost, req.SalvageValue, req.Currency); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := utils.ValidateDepreciationPolicy(req.DepreciationPolicy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !validLocation(w, req.LocationID) {
+		return
+	}
+
 	tx, err := db.DB.Begin()
 	if err != nil {
 		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
@@ -31,72 +47,140 @@ func CreateAsset(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.TxFinalizer(tx, &err)
 
-	// Get or create brand
-	brandID, err := db.GetOrCreateBrand(tx, req.Model.Brand.Name)
+	authUserID := middleware.GetUserID(r)
+	assetID, err := db.InsertAssetFromRequest(tx, &req, authUserID)
 	if err != nil {
-		http.Error(w, "failed to resolve brand", http.StatusInternalServerError)
+		if errors.Is(err, db.ErrUnknownBrand) || errors.Is(err, db.ErrUnknownModel) ||
+			errors.Is(err, utils.ErrInvalidSpecs) || errors.Is(err, db.ErrUnknownAssetType) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to create asset", http.StatusInternalServerError)
 		return
 	}
 
-	// Get or create model under the brand
-	modelID, err := db.GetOrCreateModel(tx, brandID, &req.Model)
-	if err != nil {
-		http.Error(w, "failed to resolve model", http.StatusInternalServerError)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":  "Asset created successfully",
+		"asset_id": assetID,
+	})
+}
+
+func ListAssets(w http.ResponseWriter, r *http.Request) {
+	list, ok := parseListParams(w, r, db.AssetSortFields)
+	if !ok {
 		return
 	}
 
-	// Insert into specs table based on asset_type
-	specsID, err := db.InsertSpecsAndReturnID(tx, req.Model.AssetType, req.Specs)
-	if err != nil {
-		http.Error(w, "failed to insert specs: "+err.Error(), http.StatusInternalServerError)
+	params, ok := parseAssetFilters(w, r)
+	if !ok {
 		return
 	}
+	facets, ok := parseFacets(w, r, db.AssetFacetFields)
+	if !ok {
+		return
+	}
+	params.ListParams = list
+	// one row past the page tells whether there is another
+	params.Limit++
 
-	// Insert into assets table
-	authUserID := middleware.GetUserID(r)
-	log.Println(authUserID)
-
-	//insert into asset table
-	assetID, err := db.InsertAssetAndReturnID(tx, modelID, specsID, &req, authUserID)
-
+	assets, err := db.ListAssets(&params)
 	if err != nil {
-		http.Error(w, "failed to insert asset: "+err.Error(), http.StatusInternalServerError)
+		log.Println(err.Error())
+		http.Error(w, "failed to list assets", http.StatusInternalServerError)
 		return
 	}
 
-	err = db.InsertAssetStatus(tx, assetID, "available")
+	total, err := db.CountAssets(&params)
 	if err != nil {
-		http.Error(w, "failed to insert asset status: "+err.Error(), http.StatusInternalServerError)
+		log.Println(err.Error())
+		http.Error(w, "failed to count assets", http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message":  "Asset created successfully",
-		"asset_id": assetID,
-	})
+	var facetCounts map[string][]models.FacetCount
+	if len(facets) > 0 {
+		facetCounts, err = db.AssetFacets(&params, facets)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to count asset facets", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	now := time.Now()
+	for i := range assets {
+		// disposed assets keep the value they had when they left the books, as in the detail
+		valuedAt := now
+		if assets[i].ArchivedAt != nil {
+			valuedAt = *assets[i].ArchivedAt
+		}
+		if dep := utils.Depreciate(assets[i].Cost, valuedAt); dep != nil {
+			assets[i].PurchaseCost = &dep.PurchaseCost
+			assets[i].BookValue = &dep.BookValue
+		}
+		assets[i].Currency = assets[i].Cost.Currency
+		if len(params.SpecFields) > 0 {
+			assets[i].Specs = pickSpecs(assets[i].Specs, params.SpecFields)
+		}
+	}
+
+	writeListPage(w, r, list, assets, total, facetCounts, func(a models.ListAssetsResponse) []string { return a.Cursor })
 }
 
-func ListAssets(w http.ResponseWriter, r *http.Request) {
+func GetAsset(w http.ResponseWriter, r *http.Request) {
+	writeAssetDetail(w, chi.URLParam(r, "id"))
+}
 
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-	if err != nil {
-		http.Error(w, "limit is not a number", http.StatusBadRequest)
+// writeAssetDetail responds with the full detail of an asset, shared by GetAsset and ScanAsset
+func writeAssetDetail(w http.ResponseWriter, assetID string) {
+	if !utils.IsValidUUID(assetID) {
+		http.Error(w, "asset not found", http.StatusNotFound)
 		return
 	}
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+
+	asset, err := db.GetAssetDetail(assetID)
 	if err != nil {
-		http.Error(w, "page is not a number", http.StatusBadRequest)
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset", http.StatusInternalServerError)
+		return
+	}
+	if asset == nil {
+		http.Error(w, "asset not found", http.StatusNotFound)
 		return
 	}
 
-	if page < 1 {
-		page = 1
+	own, err := db.GetSpecsByID(asset.SpecsID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset specs", http.StatusInternalServerError)
+		return
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
+	asset.ModelSpecs, err = db.GetModelDefaultSpecs(db.DB, asset.Model.ID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset specs", http.StatusInternalServerError)
+		return
 	}
+	asset.Specs = utils.MergeSpecs(asset.ModelSpecs, own)
+	asset.SpecSources = utils.SpecSources(asset.ModelSpecs, own)
+
+	asset.Warranty = utils.WarrantyStatus(asset.WarrantyStartDate, asset.WarrantyExpDate, time.Now())
 
+	// a disposed asset keeps the value it had when it left the books
+	valuedAt := time.Now()
+	if asset.ArchivedAt != nil {
+		valuedAt = *asset.ArchivedAt
+	}
+	asset.Depreciation = utils.Depreciate(asset.Cost, valuedAt)
+
+	json.NewEncoder(w).Encode(asset)
+}
+
+// parseAssetFilters reads the asset listing filters shared by ListAssets and ExportAssets.
+// Spec filters are checked against the asset types, on a bad one the error is written and ok is false.
+func parseAssetFilters(w http.ResponseWriter, r *http.Request) (params models.ListAssetsQueryParams, ok bool) {
 	parseMulti := func(param string) []string {
 		values := strings.Split(r.URL.Query().Get(param), ",")
 		var cleaned []string
@@ -109,32 +193,76 @@ func ListAssets(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse query params
-	params := models.ListAssetsQueryParams{
+	params = models.ListAssetsQueryParams{
 		Search:     r.URL.Query().Get("search"),
 		AssetTypes: parseMulti("asset_type"),
 		Status:     parseMulti("status"),
 		OwnedBy:    parseMulti("owned_by"),
-		Limit:      limit,
-		Offset:     (page - 1) * limit,
+		IDs:        parseMulti("id"),
+		Locations:  parseMulti("location_id"),
+		SerialNo:   strings.TrimSpace(r.URL.Query().Get("serial_no")),
 	}
 
-	assets, err := db.ListAssets(&params)
+	// asking for disposed assets by status implies including them
+	params.IncludeDisposed = r.URL.Query().Get("include_disposed") == "true" || slices.Contains(params.Status, "disposed")
+
+	// include_specs=true returns every spec, a list of fields only those
+	if include := r.URL.Query().Get("include_specs"); include != "" && include != "false" {
+		params.IncludeSpecs = true
+		if include != "true" {
+			params.SpecFields = parseMulti("include_specs")
+		}
+	}
+
+	// every spec param is one condition, e.g. spec=ram_gb>=16&spec=storage_type=SSD
+	exprs := r.URL.Query()["spec"]
+	if len(exprs) == 0 {
+		return params, true
+	}
+
+	defs, err := db.ListAssetTypes()
 	if err != nil {
 		log.Println(err.Error())
-		http.Error(w, "failed to list assets", http.StatusInternalServerError)
-		return
+		http.Error(w, "failed to fetch asset types", http.StatusInternalServerError)
+		return params, false
+	}
+	if len(params.AssetTypes) > 0 {
+		defs = slices.DeleteFunc(defs, func(def models.AssetTypeDefinition) bool {
+			return !slices.Contains(params.AssetTypes, def.Name)
+		})
 	}
 
-	if len(assets) == 0 {
-		http.Error(w, "no assets found", http.StatusNotFound)
-		return
+	for _, expr := range exprs {
+		filter, err := utils.ParseSpecFilter(expr)
+		if err == nil {
+			err = utils.ResolveSpecFilter(&filter, defs)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return params, false
+		}
+		params.Specs = append(params.Specs, filter)
 	}
+	return params, true
+}
 
-	json.NewEncoder(w).Encode(assets)
+// pickSpecs keeps the asked for fields of the specs of a listed asset
+func pickSpecs(specs map[string]interface{}, fields []string) map[string]interface{} {
+	picked := map[string]interface{}{}
+	for _, field := range fields {
+		if val, ok := specs[field]; ok {
+			picked[field] = val
+		}
+	}
+	return picked
 }
 
 func UpdateAsset(w http.ResponseWriter, r *http.Request) {
 	assetID := chi.URLParam(r, "id")
+	if !utils.IsValidUUID(assetID) {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
 
 	var req models.UpdateAssetRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -146,10 +274,31 @@ func UpdateAsset(w http.ResponseWriter, r *http.Request) {
 	existingAsset, err := db.GetAssetWithModel(assetID)
 	if err != nil {
 		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset", http.StatusInternalServerError)
+		return
+	}
+	if existingAsset == nil {
 		http.Error(w, "asset not found", http.StatusNotFound)
 		return
 	}
 
+	// salvage value is checked against the cost the asset ends up with
+	cost, salvage := req.PurchaseCost, req.SalvageValue
+	if cost == nil {
+		cost = existingAsset.PurchaseCost
+	}
+	if salvage == nil {
+		salvage = &existingAsset.SalvageValue
+	}
+	if err := utils.ValidateAssetCost(cost, salvage, req.Currency); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := utils.ValidateDepreciationPolicy(req.DepreciationPolicy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Start transaction
 	tx, err := db.DB.Begin()
 	if err != nil {
@@ -168,9 +317,13 @@ func UpdateAsset(w http.ResponseWriter, r *http.Request) {
 
 	// Update specs if provided
 	if req.Specs != nil {
-		err := db.UpdateSpecsByType(tx, existingAsset.AssetType, existingAsset.SpecsID, req.Specs)
+		err = db.UpdateSpecsByType(tx, existingAsset, req.Specs)
 		if err != nil {
 			log.Println(err.Error())
+			if errors.Is(err, utils.ErrInvalidSpecs) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			http.Error(w, "failed to update asset(specs)", http.StatusInternalServerError)
 			return
 		}
@@ -189,6 +342,9 @@ func AssignAsset(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid input", http.StatusBadRequest)
 		return
 	}
+	if !validLocation(w, req.LocationID) {
+		return
+	}
 
 	tx, err := db.DB.Begin()
 	if err != nil {
@@ -209,26 +365,118 @@ func AssignAsset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if asset exists and is available
-	isAvailable, err := db.IsAssetAvailable(tx, req.AssetID)
+	err = assignAssetToUser(tx, &req)
 	if err != nil {
+		if errors.Is(err, errAssetNotAvailable) {
+			http.Error(w, "Asset is not available for assignment", http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if !isAvailable {
-		http.Error(w, "Asset is not available for assignment", http.StatusBadRequest)
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte("Asset assigned successfully"))
+}
+
+// TransferAsset moves an assigned asset straight to another user in one transaction,
+// so it never shows up without a holder in between
+func TransferAsset(w http.ResponseWriter, r *http.Request) {
+	var req models.TransferAssetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.AssetID == "" || req.ToUserID == "" || req.Reason == "" {
+		http.Error(w, "asset_id, to_user_id and reason are required", http.StatusBadRequest)
+		return
+	}
+	if !validLocation(w, req.LocationID) {
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "could not begin transaction", http.StatusInternalServerError)
 		return
 	}
+	defer db.TxFinalizer(tx, &err)
 
-	// Create asset_status entry
-	if err := db.InsertAssetStatusToUser(tx, &req); err != nil {
+	err = db.IsUserExistByID(req.ToUserID, tx)
+	if err != nil {
+		log.Println(err.Error())
+		if err == sql.ErrNoRows {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "error in finding user", http.StatusInternalServerError)
+		return
+	}
+
+	current, err := db.GetActiveAssetStatus(tx, req.AssetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+		return
+	}
+	if current == nil {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+	if current.Status != "assigned" || current.AssignedToUser == nil {
+		http.Error(w, "only assigned assets can be transferred", http.StatusBadRequest)
+		return
+	}
+	if *current.AssignedToUser == req.ToUserID {
+		http.Error(w, "asset is already assigned to this user", http.StatusBadRequest)
+		return
+	}
+
+	if err = db.ArchiveAssetStatus(tx, current.ID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte("Asset assigned successfully"))
+	if err = db.InsertAssetStatusTransfer(tx, &req, *current.AssignedToUser); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to transfer asset", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":      "Asset transferred successfully",
+		"asset_id":     req.AssetID,
+		"from_user_id": *current.AssignedToUser,
+		"to_user_id":   req.ToUserID,
+	})
+}
+
+var errAssetNotAvailable = errors.New("asset is not available for assignment")
+
+// assignAssetToUser replaces the 'available' status of the asset with an assignment to the user
+func assignAssetToUser(tx *sql.Tx, req *models.AssignAssetRequest) error {
+	isAvailable, err := db.IsAssetAvailable(tx, req.AssetID)
+	if err != nil {
+		return err
+	}
+	if !isAvailable {
+		return errAssetNotAvailable
+	}
+
+	// Archive the current 'available' row so the assignment becomes the active status
+	current, err := db.GetActiveAssetStatus(tx, req.AssetID)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		if err := db.ArchiveAssetStatus(tx, current.ID); err != nil {
+			return err
+		}
+	}
+
+	return db.InsertAssetStatusToUser(tx, req)
 }
 
 func RetrieveAsset(w http.ResponseWriter, r *http.Request) {
@@ -238,6 +486,15 @@ func RetrieveAsset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var req models.RetrieveAssetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !validLocation(w, req.LocationID) {
+		return
+	}
+
 	tx, err := db.DB.Begin()
 	if err != nil {
 		http.Error(w, "could not begin transaction", http.StatusInternalServerError)
@@ -253,11 +510,24 @@ func RetrieveAsset(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set archived_at (retrieval time)
-	if err := db.ArchiveAssetStatus(tx, statusID); err != nil {
+	if err = db.ArchiveAssetStatus(tx, statusID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// Asset goes back to the available pool
+	if err = db.InsertAssetStatus(tx, assetID, "available"); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req.LocationID != nil {
+		if err = db.SetActiveStatusLocation(tx, assetID, *req.LocationID); err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to record asset location", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Asset retrieved successfully"))
 }
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"slices"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strings"
+)
+
+var (
+	ownerTypes      = []string{"remote_state", "client"}
+	auditConditions = []string{"good", "damaged", "not_working"}
+)
+
+// CreateAudit opens an audit and snapshots the assets in scope with their active status
+func CreateAudit(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAuditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	for _, assetType := range req.AssetTypes {
+		if _, err := db.GetAssetType(assetType); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, owner := range req.OwnedBy {
+		if !slices.Contains(ownerTypes, owner) {
+			http.Error(w, "owned_by must be remote_state or client", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	authUserID := middleware.GetUserID(r)
+	auditID, err := db.CreateAudit(tx, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to create audit", http.StatusInternalServerError)
+		return
+	}
+
+	expected, err := db.SnapshotAuditScope(tx, auditID, &req)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to snapshot audit scope", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"message":  "Audit opened successfully",
+		"audit_id": auditID,
+		"expected": expected,
+	})
+}
+
+func ListAudits(w http.ResponseWriter, r *http.Request) {
+	var status []string
+	for _, v := range strings.Split(r.URL.Query().Get("status"), ",") {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			status = append(status, trimmed)
+		}
+	}
+
+	audits, err := db.ListAudits(status)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list audits", http.StatusInternalServerError)
+		return
+	}
+	if len(audits) == 0 {
+		http.Error(w, "no audits found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(audits)
+}
+
+func GetAudit(w http.ResponseWriter, r *http.Request) {
+	auditID := chi.URLParam(r, "audit_id")
+	if !utils.IsValidUUID(auditID) {
+		http.Error(w, "audit not found", http.StatusNotFound)
+		return
+	}
+
+	audit, err := db.GetAudit(auditID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch audit", http.StatusInternalServerError)
+		return
+	}
+	if audit == nil {
+		http.Error(w, "audit not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(audit)
+}
+
+// ScanAudit records an asset as seen during an open audit, by its scanned id or serial number.
+// Codes matching no asset are kept too and show up as unexpected in the report.
+func ScanAudit(w http.ResponseWriter, r *http.Request) {
+	auditID := chi.URLParam(r, "audit_id")
+	if !utils.IsValidUUID(auditID) {
+		http.Error(w, "audit not found", http.StatusNotFound)
+		return
+	}
+
+	var req models.AuditScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Code = strings.TrimSpace(req.Code)
+	if req.Code == "" {
+		http.Error(w, "code is required", http.StatusBadRequest)
+		return
+	}
+	if req.Condition == "" {
+		req.Condition = "good"
+	}
+	if !slices.Contains(auditConditions, req.Condition) {
+		http.Error(w, "condition must be good, damaged or not_working", http.StatusBadRequest)
+		return
+	}
+	if req.SeenWithUser != nil && !utils.IsValidUUID(*req.SeenWithUser) {
+		http.Error(w, "seen_with_user is not a valid id", http.StatusBadRequest)
+		return
+	}
+
+	assets, err := findAssetsByCode(req.Code)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to look up asset", http.StatusInternalServerError)
+		return
+	}
+	if len(assets) > 1 {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(assets)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	audit, err := db.LockAudit(tx, auditID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch audit", http.StatusInternalServerError)
+		return
+	}
+	if audit == nil {
+		http.Error(w, "audit not found", http.StatusNotFound)
+		return
+	}
+	if audit.Status != "open" {
+		http.Error(w, "audit is already "+audit.Status, http.StatusBadRequest)
+		return
+	}
+
+	if req.SeenWithUser != nil {
+		if err = db.IsUserExistByID(*req.SeenWithUser, tx); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.Error(w, "seen_with_user not found", http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "error in finding user", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var assetID *string
+	var current *models.AssetStatus
+	if len(assets) == 1 {
+		assetID = &assets[0].ID
+		current, err = db.GetActiveAssetStatus(tx, *assetID)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	authUserID := middleware.GetUserID(r)
+	if err = db.RecordAuditScan(tx, auditID, assetID, &req, current, authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to record scan", http.StatusInternalServerError)
+		return
+	}
+
+	resp := map[string]any{"message": "Scan recorded successfully", "known": assetID != nil}
+	if assetID != nil {
+		resp["asset_id"] = *assetID
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// CloseAudit ends an audit, no more scans are taken and the report is final
+func CloseAudit(w http.ResponseWriter, r *http.Request) {
+	auditID := chi.URLParam(r, "audit_id")
+	if !utils.IsValidUUID(auditID) {
+		http.Error(w, "audit not found", http.StatusNotFound)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	audit, err := db.LockAudit(tx, auditID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch audit", http.StatusInternalServerError)
+		return
+	}
+	if audit == nil {
+		http.Error(w, "audit not found", http.StatusNotFound)
+		return
+	}
+	if audit.Status != "open" {
+		http.Error(w, "audit is already "+audit.Status, http.StatusBadRequest)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	if err = db.CloseAudit(tx, auditID, authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to close audit", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte("audit closed successfully"))
+}
+
+// AuditReport reconciles the scans with the assets expected when the audit was opened
+func AuditReport(w http.ResponseWriter, r *http.Request) {
+	auditID := chi.URLParam(r, "audit_id")
+	if !utils.IsValidUUID(auditID) {
+		http.Error(w, "audit not found", http.StatusNotFound)
+		return
+	}
+
+	report, err := db.GetAuditReport(auditID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to build audit report", http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		http.Error(w, "audit not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+// AssetAuditHistory lists every audit the asset was seen in, with where and in what condition
+func AssetAuditHistory(w http.ResponseWriter, r *http.Request) {
+	assetID := chi.URLParam(r, "asset_id")
+	if !utils.IsValidUUID(assetID) {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+
+	history, err := db.ListAssetAuditHistory(assetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch audit history", http.StatusInternalServerError)
+		return
+	}
+	if len(history) == 0 {
+		http.Error(w, "no audit history found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(history)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/models"
+	"storex/utils"
+)
+
+// maxBulkItems keeps a bulk call inside one reasonably short transaction
+const maxBulkItems = 500
+
+// BulkAssignAssets assigns every (asset_id, user_id) pair or none of them.
+// Each item is validated first, when any fails the per-item results come back with 422.
+func BulkAssignAssets(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkAssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	if len(req.Items) == 0 || len(req.Items) > maxBulkItems {
+		http.Error(w, "items must contain between 1 and 500 assignments", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "could not begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	result := models.BulkResult{Results: make([]models.BulkItemResult, len(req.Items))}
+	seen := map[string]bool{}
+	failed := false
+	for i, item := range req.Items {
+		msg, verr := validateBulkAssignment(tx, &item, seen)
+		if verr != nil {
+			err = verr
+			log.Println(err.Error())
+			http.Error(w, "failed to validate assignments", http.StatusInternalServerError)
+			return
+		}
+		result.Results[i] = models.BulkItemResult{AssetID: item.AssetID, UserID: item.UserID, OK: msg == "", Error: msg}
+		failed = failed || msg != ""
+	}
+
+	if failed {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	for i := range req.Items {
+		if err = assignAssetToUser(tx, &req.Items[i]); err != nil {
+			log.Println(err.Error())
+			result.Results[i].OK = false
+			result.Results[i].Error = "failed to assign asset"
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(result)
+			return
+		}
+	}
+
+	result.Applied = true
+	json.NewEncoder(w).Encode(result)
+}
+
+// validateBulkAssignment returns why the item cannot be assigned, an error only when the lookup itself failed
+func validateBulkAssignment(tx *sql.Tx, item *models.AssignAssetRequest, seen map[string]bool) (string, error) {
+	if !utils.IsValidUUID(item.AssetID) || !utils.IsValidUUID(item.UserID) {
+		return "asset_id and user_id must be valid ids", nil
+	}
+	if seen[item.AssetID] {
+		return "asset appears more than once", nil
+	}
+	seen[item.AssetID] = true
+
+	if item.LocationID != nil {
+		loc, err := db.GetLocation(*item.LocationID)
+		if err != nil {
+			return "", err
+		}
+		if loc == nil {
+			return "location not found", nil
+		}
+	}
+
+	if err := db.IsUserExistByID(item.UserID, tx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "user not found", nil
+		}
+		return "", err
+	}
+
+	current, err := db.GetActiveAssetStatus(tx, item.AssetID)
+	if err != nil {
+		return "", err
+	}
+	if current == nil {
+		return "asset not found", nil
+	}
+
+	isAvailable, err := db.IsAssetAvailable(tx, item.AssetID)
+	if err != nil {
+		return "", err
+	}
+	if !isAvailable {
+		return "asset is " + current.Status + ", not available", nil
+	}
+	return "", nil
+}
+
+// BulkRetrieveAssets brings every listed asset back to the available pool or none of them
+func BulkRetrieveAssets(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkRetrieveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	if len(req.AssetIDs) == 0 || len(req.AssetIDs) > maxBulkItems {
+		http.Error(w, "asset_ids must contain between 1 and 500 ids", http.StatusBadRequest)
+		return
+	}
+	if !validLocation(w, req.LocationID) {
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "could not begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	result := models.BulkResult{Results: make([]models.BulkItemResult, len(req.AssetIDs))}
+	statuses := make([]*models.AssetStatus, len(req.AssetIDs))
+	seen := map[string]bool{}
+	failed := false
+	for i, assetID := range req.AssetIDs {
+		item := models.BulkItemResult{AssetID: assetID}
+
+		switch {
+		case !utils.IsValidUUID(assetID):
+			item.Error = "asset_id must be a valid id"
+		case seen[assetID]:
+			item.Error = "asset appears more than once"
+		default:
+			seen[assetID] = true
+			statuses[i], err = db.GetActiveAssetStatus(tx, assetID)
+			if err != nil {
+				log.Println(err.Error())
+				http.Error(w, "failed to validate retrievals", http.StatusInternalServerError)
+				return
+			}
+			if statuses[i] == nil {
+				item.Error = "asset not found"
+			} else if statuses[i].Status != "assigned" {
+				item.Error = "asset is " + statuses[i].Status + ", not assigned"
+			} else {
+				item.UserID = *statuses[i].AssignedToUser
+			}
+		}
+
+		item.OK = item.Error == ""
+		failed = failed || !item.OK
+		result.Results[i] = item
+	}
+
+	if failed {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	for i, assetID := range req.AssetIDs {
+		if err = db.ArchiveAssetStatus(tx, statuses[i].ID); err == nil {
+			err = db.InsertAssetStatus(tx, assetID, "available")
+		}
+		if err == nil && req.LocationID != nil {
+			err = db.SetActiveStatusLocation(tx, assetID, *req.LocationID)
+		}
+		if err != nil {
+			log.Println(err.Error())
+			result.Results[i].OK = false
+			result.Results[i].Error = "failed to retrieve asset"
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(result)
+			return
+		}
+	}
+
+	result.Applied = true
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,555 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strings"
+)
+
+func ListBrands(w http.ResponseWriter, r *http.Request) {
+	brands, err := db.ListBrands(strings.TrimSpace(r.URL.Query().Get("search")))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list brands", http.StatusInternalServerError)
+		return
+	}
+	if len(brands) == 0 {
+		http.Error(w, "no brands found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(brands)
+}
+
+func GetBrand(w http.ResponseWriter, r *http.Request) {
+	brand, err := db.GetBrand(chi.URLParam(r, "brand_id"))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch brand", http.StatusInternalServerError)
+		return
+	}
+	if brand == nil {
+		http.Error(w, "brand not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(brand)
+}
+
+func CreateBrand(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateBrandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	taken, err := db.IsBrandNameTaken(tx, req.Name, "")
+	if err != nil {
+		http.Error(w, "failed to check brand name", http.StatusInternalServerError)
+		return
+	}
+	if taken {
+		http.Error(w, "a brand with this name already exists", http.StatusConflict)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	brandID, err := db.CreateBrand(tx, req.Name, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to create brand", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":  "Brand created successfully",
+		"brand_id": brandID,
+	})
+}
+
+// UpdateBrand renames a brand, fixing a typo in every asset of the brand at once
+func UpdateBrand(w http.ResponseWriter, r *http.Request) {
+	brandID := chi.URLParam(r, "brand_id")
+
+	var req models.UpdateBrandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	taken, err := db.IsBrandNameTaken(tx, req.Name, brandID)
+	if err != nil {
+		http.Error(w, "failed to check brand name", http.StatusInternalServerError)
+		return
+	}
+	if taken {
+		http.Error(w, "a brand with this name already exists, merge the brands instead", http.StatusConflict)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	updated, err := db.RenameBrand(tx, brandID, req.Name, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update brand", http.StatusInternalServerError)
+		return
+	}
+	if updated == 0 {
+		http.Error(w, "brand not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Brand updated successfully",
+	})
+}
+
+// DeleteBrand removes a brand without models, others have to be merged away
+func DeleteBrand(w http.ResponseWriter, r *http.Request) {
+	brandID := chi.URLParam(r, "brand_id")
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	brand, err := db.LockBrand(tx, brandID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch brand", http.StatusInternalServerError)
+		return
+	}
+	if brand == nil {
+		http.Error(w, "brand not found", http.StatusNotFound)
+		return
+	}
+
+	deleted, err := db.DeleteBrand(tx, brandID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to delete brand", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "brand still has models, merge it into another brand instead", http.StatusConflict)
+		return
+	}
+
+	w.Write([]byte("brand deleted successfully"))
+}
+
+// MergeBrand folds a duplicate brand into another one, repointing its models and assets
+func MergeBrand(w http.ResponseWriter, r *http.Request) {
+	sourceID := chi.URLParam(r, "brand_id")
+
+	var req models.MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.IntoID == "" {
+		http.Error(w, "into_id is required", http.StatusBadRequest)
+		return
+	}
+	if req.IntoID == sourceID {
+		http.Error(w, "a brand cannot be merged into itself", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	source, err := db.LockBrand(tx, sourceID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch brand", http.StatusInternalServerError)
+		return
+	}
+	if source == nil {
+		http.Error(w, "brand not found", http.StatusNotFound)
+		return
+	}
+	target, err := db.LockBrand(tx, req.IntoID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch brand", http.StatusInternalServerError)
+		return
+	}
+	if target == nil {
+		http.Error(w, "brand to merge into not found", http.StatusBadRequest)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	result, err := db.MergeBrands(tx, source.ID, target.ID, authUserID)
+	if err != nil {
+		if errors.Is(err, db.ErrCatalogConflict) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to merge brands", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func ListModels(w http.ResponseWriter, r *http.Request) {
+	parseMulti := func(param string) []string {
+		var cleaned []string
+		for _, v := range strings.Split(r.URL.Query().Get(param), ",") {
+			if trimmed := strings.TrimSpace(v); trimmed != "" {
+				cleaned = append(cleaned, trimmed)
+			}
+		}
+		return cleaned
+	}
+
+	modelList, err := db.ListModels(&models.ListModelsQueryParams{
+		Search:     strings.TrimSpace(r.URL.Query().Get("search")),
+		BrandIDs:   parseMulti("brand_id"),
+		AssetTypes: parseMulti("asset_type"),
+	})
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list models", http.StatusInternalServerError)
+		return
+	}
+	if len(modelList) == 0 {
+		http.Error(w, "no models found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(modelList)
+}
+
+func GetModel(w http.ResponseWriter, r *http.Request) {
+	model, err := db.GetModel(chi.URLParam(r, "model_id"))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch model", http.StatusInternalServerError)
+		return
+	}
+	if model == nil {
+		http.Error(w, "model not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(model)
+}
+
+func CreateModel(w http.ResponseWriter, r *http.Request) {
+	var req models.CatalogModelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.BrandID == "" {
+		http.Error(w, "name and brand_id are required", http.StatusBadRequest)
+		return
+	}
+	def, err := db.GetAssetType(req.AssetType)
+	if err != nil {
+		if errors.Is(err, db.ErrUnknownAssetType) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset type", http.StatusInternalServerError)
+		return
+	}
+	// defaults are a partial spec document, the assets fill in the rest
+	req.DefaultSpecs, err = utils.ValidateSpecs(def.Fields, req.DefaultSpecs, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	brand, err := db.LockBrand(tx, req.BrandID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch brand", http.StatusInternalServerError)
+		return
+	}
+	if brand == nil {
+		http.Error(w, "brand not found", http.StatusBadRequest)
+		return
+	}
+
+	taken, err := db.IsModelNameTaken(tx, brand.ID, req.Name, "")
+	if err != nil {
+		http.Error(w, "failed to check model name", http.StatusInternalServerError)
+		return
+	}
+	if taken {
+		http.Error(w, "the brand already has a model with this name", http.StatusConflict)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	modelID, err := db.CreateModel(tx, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to create model", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":  "Model created successfully",
+		"model_id": modelID,
+	})
+}
+
+// UpdateModel renames a model, moves it to another brand or changes its default specs. Its asset type
+// can only change while no asset uses it, since the specs of its assets follow the type.
+func UpdateModel(w http.ResponseWriter, r *http.Request) {
+	modelID := chi.URLParam(r, "model_id")
+
+	var req models.UpdateModelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+		if *req.Name == "" {
+			http.Error(w, "name cannot be empty", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	model, err := db.LockModel(tx, modelID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch model", http.StatusInternalServerError)
+		return
+	}
+	if model == nil {
+		http.Error(w, "model not found", http.StatusNotFound)
+		return
+	}
+	if req.AssetType != nil && *req.AssetType != model.AssetType && model.AssetCount > 0 {
+		http.Error(w, "asset_type cannot change while assets use the model", http.StatusConflict)
+		return
+	}
+
+	assetType := model.AssetType
+	if req.AssetType != nil {
+		assetType = *req.AssetType
+	}
+	def, err := db.GetAssetType(assetType)
+	if err != nil {
+		if errors.Is(err, db.ErrUnknownAssetType) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset type", http.StatusInternalServerError)
+		return
+	}
+
+	// the kept defaults have to fit a new asset type as well
+	defaults := utils.MergeSpecs(model.DefaultSpecs, req.DefaultSpecs)
+	defaults, err = utils.ValidateSpecs(def.Fields, defaults, true)
+	if err != nil {
+		http.Error(w, "default_specs: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	brandID, name := model.BrandID, model.Name
+	if req.BrandID != nil {
+		brand, err := db.LockBrand(tx, *req.BrandID)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to fetch brand", http.StatusInternalServerError)
+			return
+		}
+		if brand == nil {
+			http.Error(w, "brand not found", http.StatusBadRequest)
+			return
+		}
+		brandID = brand.ID
+	}
+	if req.Name != nil {
+		name = *req.Name
+	}
+
+	taken, err := db.IsModelNameTaken(tx, brandID, name, modelID)
+	if err != nil {
+		http.Error(w, "failed to check model name", http.StatusInternalServerError)
+		return
+	}
+	if taken {
+		http.Error(w, "the brand already has a model with this name, merge the models instead", http.StatusConflict)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	if err = db.UpdateModel(tx, modelID, &req, authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update model", http.StatusInternalServerError)
+		return
+	}
+	if req.DefaultSpecs != nil {
+		if err = db.SetModelDefaultSpecs(tx, model, defaults, authUserID); err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to update model default specs", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Model updated successfully",
+	})
+}
+
+// DeleteModel removes a model no asset uses, others have to be merged away
+func DeleteModel(w http.ResponseWriter, r *http.Request) {
+	modelID := chi.URLParam(r, "model_id")
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	model, err := db.LockModel(tx, modelID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch model", http.StatusInternalServerError)
+		return
+	}
+	if model == nil {
+		http.Error(w, "model not found", http.StatusNotFound)
+		return
+	}
+
+	deleted, err := db.DeleteModel(tx, modelID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to delete model", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "assets still use the model, merge it into another model instead", http.StatusConflict)
+		return
+	}
+
+	w.Write([]byte("model deleted successfully"))
+}
+
+// MergeModel folds a duplicate model into another one of the same asset type, repointing its assets
+func MergeModel(w http.ResponseWriter, r *http.Request) {
+	sourceID := chi.URLParam(r, "model_id")
+
+	var req models.MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.IntoID == "" {
+		http.Error(w, "into_id is required", http.StatusBadRequest)
+		return
+	}
+	if req.IntoID == sourceID {
+		http.Error(w, "a model cannot be merged into itself", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	source, err := db.LockModel(tx, sourceID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch model", http.StatusInternalServerError)
+		return
+	}
+	if source == nil {
+		http.Error(w, "model not found", http.StatusNotFound)
+		return
+	}
+	target, err := db.LockModel(tx, req.IntoID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch model", http.StatusInternalServerError)
+		return
+	}
+	if target == nil {
+		http.Error(w, "model to merge into not found", http.StatusBadRequest)
+		return
+	}
+	if source.AssetType != target.AssetType {
+		http.Error(w, "models of different asset types cannot be merged", http.StatusConflict)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	result, err := db.MergeModels(tx, source.ID, target.ID, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to merge models", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
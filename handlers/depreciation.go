@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"storex/db"
+	"storex/models"
+	"storex/utils"
+	"strconv"
+	"time"
+)
+
+var depreciationColumns = []string{
+	"asset_id", "serial_no", "brand", "model", "asset_type", "purchased_date",
+	"purchase_cost", "currency", "depreciation_method", "fiscal_year",
+	"opening_value", "depreciation", "closing_value", "disposed_at",
+}
+
+// DepreciationSchedule reports the opening value, depreciation and closing value of every costed asset
+// for one fiscal year (?fiscal_year=2025 is the year starting in 2025), as JSON or CSV (?format=csv).
+func DepreciationSchedule(w http.ResponseWriter, r *http.Request) {
+	startMonth := utils.FiscalYearStartMonth()
+	year := utils.CurrentFiscalYear(time.Now(), startMonth)
+
+	if v := r.URL.Query().Get("fiscal_year"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1900 || parsed > 9999 {
+			http.Error(w, "fiscal_year must be a year like 2025", http.StatusBadRequest)
+			return
+		}
+		year = parsed
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		http.Error(w, "format must be json or csv", http.StatusBadRequest)
+		return
+	}
+
+	from, to := utils.FiscalYear(year, startMonth)
+	assets, err := db.ListDepreciableAssets(from, to)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch depreciation schedule", http.StatusInternalServerError)
+		return
+	}
+
+	label := utils.FiscalYearLabel(year, startMonth)
+	schedule := []models.DepreciationScheduleRow{}
+	for _, asset := range assets {
+		schedule = append(schedule, depreciationRow(asset, from, to, label))
+	}
+
+	if format == "json" {
+		json.NewEncoder(w).Encode(schedule)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=depreciation-%s.csv", label))
+
+	writer := csv.NewWriter(w)
+	writer.Write(depreciationColumns)
+	for _, row := range schedule {
+		disposedAt := ""
+		if row.DisposedAt != nil {
+			disposedAt = row.DisposedAt.Format("2006-01-02")
+		}
+		money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+		writer.Write([]string{
+			row.AssetID, row.SerialNo, row.BrandName, row.ModelName, row.AssetType, row.PurchasedDate.Format("2006-01-02"),
+			money(row.PurchaseCost), row.Currency, row.Method, row.FiscalYear,
+			money(row.OpeningValue), money(row.Depreciation), money(row.ClosingValue), disposedAt,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Println("depreciation export failed:", err)
+	}
+}
+
+// depreciationRow values an asset over [from, to), assets bought or disposed during the year
+// are valued from their purchase and up to their disposal
+func depreciationRow(asset models.DepreciableAsset, from time.Time, to time.Time, label string) models.DepreciationScheduleRow {
+	cost := asset.Cost
+
+	opening := *cost.PurchaseCost
+	if cost.PurchasedDate.Before(from) {
+		opening = utils.BookValue(cost, from)
+	}
+
+	closingAt := to
+	if asset.ArchivedAt != nil && asset.ArchivedAt.Before(to) {
+		closingAt = *asset.ArchivedAt
+	}
+	closing := utils.BookValue(cost, closingAt)
+
+	method := "none"
+	if cost.Policy.Method != nil {
+		method = *cost.Policy.Method
+	}
+
+	return models.DepreciationScheduleRow{
+		AssetID:       asset.AssetID,
+		SerialNo:      asset.SerialNo,
+		BrandName:     asset.BrandName,
+		ModelName:     asset.ModelName,
+		AssetType:     asset.AssetType,
+		PurchasedDate: cost.PurchasedDate,
+		PurchaseCost:  *cost.PurchaseCost,
+		Currency:      cost.Currency,
+		Method:        method,
+		FiscalYear:    label,
+		OpeningValue:  opening,
+		Depreciation:  math.Round((opening-closing)*100) / 100,
+		ClosingValue:  closing,
+		DisposedAt:    asset.ArchivedAt,
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"slices"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strings"
+)
+
+var disposalReasons = []string{"e_waste", "sold", "lost", "stolen", "returned_to_client"}
+
+func DisposeAsset(w http.ResponseWriter, r *http.Request) {
+	assetID := chi.URLParam(r, "asset_id")
+	if !utils.IsValidUUID(assetID) {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+
+	var req models.DisposeAssetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !slices.Contains(disposalReasons, req.Reason) {
+		http.Error(w, "invalid disposal reason", http.StatusBadRequest)
+		return
+	}
+	if !utils.IsValidUUID(req.ApprovedBy) {
+		http.Error(w, "approved_by must be a user id", http.StatusBadRequest)
+		return
+	}
+	if req.SaleValue != nil && (req.Reason != "sold" || *req.SaleValue < 0) {
+		http.Error(w, "sale_value is only allowed for sold assets and cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	// the lock makes a concurrent disposal of the same asset wait and then see it disposed
+	exists, disposed, err := db.LockAssetForDisposal(tx, assetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+	if disposed {
+		http.Error(w, "asset is already disposed", http.StatusConflict)
+		return
+	}
+
+	err = db.IsUserExistByID(req.ApprovedBy, tx)
+	if err != nil {
+		log.Println(err.Error())
+		if err == sql.ErrNoRows {
+			http.Error(w, "approver not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "error in finding approver", http.StatusInternalServerError)
+		return
+	}
+
+	current, err := db.GetActiveAssetStatus(tx, assetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+		return
+	}
+
+	if current != nil {
+		// Assets must be retrieved from users and vendors first
+		if current.Status == "assigned" {
+			http.Error(w, "asset cannot be disposed while assigned", http.StatusBadRequest)
+			return
+		}
+		if current.Status == "service" {
+			http.Error(w, "asset cannot be disposed while at a service vendor", http.StatusBadRequest)
+			return
+		}
+
+		if err = db.ArchiveAssetStatus(tx, current.ID); err != nil {
+			http.Error(w, "failed to archive asset status", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	authUserID := middleware.GetUserID(r)
+	disposalID, err := recordDisposal(tx, assetID, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to record disposal", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":     "Asset disposed successfully",
+		"asset_id":    assetID,
+		"disposal_id": disposalID,
+	})
+}
+
+// recordDisposal gives the asset its final 'disposed' status, records why and archives it.
+// The previous active status must already be archived.
+func recordDisposal(tx *sql.Tx, assetID string, req *models.DisposeAssetRequest, authUserID string) (string, error) {
+	if err := db.InsertAssetStatusWithRemarks(tx, assetID, "disposed", req.Remarks); err != nil {
+		return "", err
+	}
+
+	disposalID, err := db.InsertAssetDisposal(tx, assetID, req, authUserID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := db.ArchiveAsset(tx, assetID, authUserID); err != nil {
+		return "", err
+	}
+	return disposalID, nil
+}
+
+func ListAssetDisposals(w http.ResponseWriter, r *http.Request) {
+	var reasons []string
+	for _, v := range strings.Split(r.URL.Query().Get("reason"), ",") {
+		trimmed := strings.TrimSpace(v)
+		if trimmed == "" {
+			continue
+		}
+		if !slices.Contains(disposalReasons, trimmed) {
+			http.Error(w, "invalid disposal reason "+trimmed, http.StatusBadRequest)
+			return
+		}
+		reasons = append(reasons, trimmed)
+	}
+
+	disposals, err := db.ListAssetDisposals(reasons)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list disposals", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(disposals)
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"log"
+	"net/http"
+	"slices"
+	"storex/db"
+	"storex/models"
+	"strconv"
+	"time"
+)
+
+// exportFlushEvery controls how often buffered rows are pushed to the client
+const exportFlushEvery = 500
+
+// maxXLSXRows caps spreadsheet exports; excelize assembles the whole workbook in memory
+// before writing it out, so larger exports have to use csv or ndjson which stream
+const maxXLSXRows = 50000
+
+// exportColumns mirror the import file headers so an export can be edited and imported again
+var exportColumns = []string{
+	"id", "brand", "model", "asset_type", "serial_no", "owned_by",
+	"purchased_date", "warranty_start_date", "warranty_exp_date",
+	"status", "assigned_user_name", "assigned_user_email", "status_since", "location",
+	"purchase_cost", "currency", "salvage_value",
+}
+
+func ExportAssets(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" && format != "ndjson" {
+		http.Error(w, "format must be csv, xlsx or ndjson", http.StatusBadRequest)
+		return
+	}
+
+	params, ok := parseAssetFilters(w, r)
+	if !ok {
+		return
+	}
+
+	specFields, err := exportSpecFields(params.AssetTypes)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to resolve asset types", http.StatusInternalServerError)
+		return
+	}
+
+	if format == "xlsx" {
+		total, err := db.CountAssets(&params)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to count assets", http.StatusInternalServerError)
+			return
+		}
+		if total > maxXLSXRows {
+			http.Error(w, fmt.Sprintf("xlsx exports are limited to %d assets, narrow the filters or use format=csv or format=ndjson", maxXLSXRows), http.StatusBadRequest)
+			return
+		}
+	}
+
+	fileName := fmt.Sprintf("inventory-%s.%s", time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Disposition", "attachment; filename="+fileName)
+
+	switch format {
+	case "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		err = exportNDJSON(w, &params)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		err = exportCSV(w, &params, specFields)
+	case "xlsx":
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		err = exportXLSX(w, &params, specFields)
+	}
+
+	// headers are already sent at this point, all we can do is log
+	if err != nil {
+		log.Println("asset export failed:", err)
+	}
+}
+
+// exportSpecFields collects the spec field names of the exported asset types in registry order
+func exportSpecFields(assetTypes []string) ([]string, error) {
+	defs, err := db.ListAssetTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for _, def := range defs {
+		if len(assetTypes) > 0 && !slices.Contains(assetTypes, def.Name) {
+			continue
+		}
+		for _, f := range def.Fields {
+			if !slices.Contains(fields, f.Name) {
+				fields = append(fields, f.Name)
+			}
+		}
+	}
+	return fields, nil
+}
+
+func exportHeader(specFields []string) []string {
+	header := slices.Clone(exportColumns)
+	for _, f := range specFields {
+		header = append(header, "spec_"+f)
+	}
+	return header
+}
+
+func exportRecord(row *models.AssetExportRow, specFields []string) []string {
+	formatDate := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	}
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	formatAmount := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', 2, 64)
+	}
+
+	record := []string{
+		row.ID, row.BrandName, row.ModelName, row.AssetType, row.SerialNo, row.OwnedBy,
+		formatDate(&row.PurchasedDate), formatDate(row.WarrantyStartDate), formatDate(row.WarrantyExpDate),
+		deref(row.Status), deref(row.AssignedUserName), deref(row.AssignedUserEmail), formatDate(row.StatusSince), deref(row.LocationPath),
+		formatAmount(row.PurchaseCost), row.Currency, formatAmount(&row.SalvageValue),
+	}
+
+	for _, f := range specFields {
+		switch v := row.Specs[f].(type) {
+		case nil:
+			record = append(record, "")
+		case float64:
+			record = append(record, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			record = append(record, fmt.Sprint(v))
+		}
+	}
+	return record
+}
+
+func exportCSV(w http.ResponseWriter, params *models.ListAssetsQueryParams, specFields []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportHeader(specFields)); err != nil {
+		return err
+	}
+
+	count := 0
+	err := db.StreamAssets(params, func(row *models.AssetExportRow) error {
+		if err := writer.Write(exportRecord(row, specFields)); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushEvery == 0 {
+			writer.Flush()
+			flushResponse(w)
+		}
+		return nil
+	})
+	writer.Flush()
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// xlsxRecord is exportRecord with typed cells, so dates and amounts stay sortable and summable in a spreadsheet
+func xlsxRecord(row *models.AssetExportRow, specFields []string, dateStyle int) []interface{} {
+	date := func(t *time.Time) interface{} {
+		if t == nil {
+			return nil
+		}
+		return excelize.Cell{StyleID: dateStyle, Value: *t}
+	}
+	text := func(s *string) interface{} {
+		if s == nil {
+			return nil
+		}
+		return *s
+	}
+	amount := func(v *float64) interface{} {
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+
+	record := []interface{}{
+		row.ID, row.BrandName, row.ModelName, row.AssetType, row.SerialNo, row.OwnedBy,
+		date(&row.PurchasedDate), date(row.WarrantyStartDate), date(row.WarrantyExpDate),
+		text(row.Status), text(row.AssignedUserName), text(row.AssignedUserEmail), date(row.StatusSince), text(row.LocationPath),
+		amount(row.PurchaseCost), row.Currency, row.SalvageValue,
+	}
+
+	for _, f := range specFields {
+		switch v := row.Specs[f].(type) {
+		case nil, float64, bool, string:
+			record = append(record, v)
+		default:
+			record = append(record, fmt.Sprint(v))
+		}
+	}
+	return record
+}
+
+func exportXLSX(w http.ResponseWriter, params *models.ListAssetsQueryParams, specFields []string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	// the stream writer keeps rows out of the sheet model, but the workbook is still zipped
+	// in memory on write, which is why ExportAssets caps xlsx at maxXLSXRows
+	sheet := "Inventory"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+	dateFormat := "yyyy-mm-dd"
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return err
+	}
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	header := exportHeader(specFields)
+	headerCells := make([]interface{}, len(header))
+	for i, v := range header {
+		headerCells[i] = v
+	}
+
+	line := 1
+	if err := sw.SetRow("A1", headerCells); err != nil {
+		return err
+	}
+
+	err = db.StreamAssets(params, func(row *models.AssetExportRow) error {
+		line++
+		cell, err := excelize.CoordinatesToCellName(1, line)
+		if err != nil {
+			return err
+		}
+		return sw.SetRow(cell, xlsxRecord(row, specFields, dateStyle))
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	_, err = f.WriteTo(w)
+	return err
+}
+
+func exportNDJSON(w http.ResponseWriter, params *models.ListAssetsQueryParams) error {
+	encoder := json.NewEncoder(w)
+
+	count := 0
+	return db.StreamAssets(params, func(row *models.AssetExportRow) error {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushEvery == 0 {
+			flushResponse(w)
+		}
+		return nil
+	})
+}
+
+func flushResponse(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strconv"
+	"strings"
+)
+
+// AcknowledgeHandover lets an employee confirm receipt of an asset assigned to them with a typed signature
+func AcknowledgeHandover(w http.ResponseWriter, r *http.Request) {
+	assetID := chi.URLParam(r, "asset_id")
+	if !utils.IsValidUUID(assetID) {
+		http.Error(w, "no pending handover for this asset", http.StatusNotFound)
+		return
+	}
+
+	var req models.AcknowledgeHandoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Signature = strings.TrimSpace(req.Signature)
+	if req.Signature == "" {
+		http.Error(w, "signature is required", http.StatusBadRequest)
+		return
+	}
+
+	userID := middleware.GetUserID(r)
+	ok, err := db.AcknowledgeHandover(assetID, userID, req.Signature)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to acknowledge handover", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "no pending handover for this asset", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":  "Handover acknowledged successfully",
+		"asset_id": assetID,
+	})
+}
+
+// ListPendingHandovers lists assignments the employee has not acknowledged, ?older_than_days narrows it to stale ones
+func ListPendingHandovers(w http.ResponseWriter, r *http.Request) {
+	minDays := 0
+	if v := r.URL.Query().Get("older_than_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 0 {
+			http.Error(w, "older_than_days must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		minDays = days
+	}
+
+	handovers, err := db.ListPendingHandovers(minDays)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list pending handovers", http.StatusInternalServerError)
+		return
+	}
+	if handovers == nil {
+		handovers = []models.PendingHandover{}
+	}
+
+	json.NewEncoder(w).Encode(handovers)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/importer"
+	"storex/middleware"
+	"storex/utils"
+	"strconv"
+)
+
+// maxImportFileSize caps uploads at 20MB, a few thousand assets
+const maxImportFileSize = 20 << 20
+
+func ImportAssets(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "failed to read file", http.StatusBadRequest)
+		return
+	}
+
+	batchSize := 0
+	if v := r.URL.Query().Get("batch_size"); v != "" {
+		batchSize, err = strconv.Atoi(v)
+		if err != nil || batchSize < 0 {
+			http.Error(w, "batch_size is not a valid number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	importID := r.URL.Query().Get("import_id")
+	if importID != "" && !utils.IsValidUUID(importID) {
+		http.Error(w, "import not found", http.StatusNotFound)
+		return
+	}
+
+	opts := importer.Options{
+		FileName:  header.Filename,
+		CreatedBy: middleware.GetUserID(r),
+		DryRun:    r.URL.Query().Get("dry_run") == "true",
+		BatchSize: batchSize,
+		ImportID:  importID,
+	}
+
+	report, err := importer.Run(data, opts)
+	if err != nil {
+		if errors.Is(err, importer.ErrInvalidFile) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, importer.ErrImportNotFound) {
+			http.Error(w, "import not found", http.StatusNotFound)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to import assets", http.StatusInternalServerError)
+		return
+	}
+
+	switch report.Status {
+	case "invalid":
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	case "failed":
+		log.Println("asset import failed:", report.Error)
+		w.WriteHeader(http.StatusInternalServerError)
+	case "completed":
+		if !opts.DryRun && report.ImportedRows > 0 {
+			w.WriteHeader(http.StatusCreated)
+		}
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+func GetAssetImport(w http.ResponseWriter, r *http.Request) {
+	importID := chi.URLParam(r, "import_id")
+	if !utils.IsValidUUID(importID) {
+		http.Error(w, "import not found", http.StatusNotFound)
+		return
+	}
+
+	imp, err := db.GetAssetImport(importID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch import", http.StatusInternalServerError)
+		return
+	}
+	if imp == nil {
+		http.Error(w, "import not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(imp)
+}
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"slices"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strings"
+)
+
+var userTypes = []string{"full_time", "intern", "freelancer"}
+
+// errInvalidKit marks kit errors the caller can fix, anything else is a failed lookup
+var errInvalidKit = errors.New("invalid kit")
+
+// validateKitItems checks every kit line against the asset type registry
+func validateKitItems(items []models.KitItem) error {
+	if len(items) == 0 {
+		return fmt.Errorf("%w: a kit needs at least one item", errInvalidKit)
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+		if item.Quantity < 0 {
+			return fmt.Errorf("%w: item %d: quantity must be positive", errInvalidKit, i+1)
+		}
+
+		def, err := db.GetAssetType(item.AssetType)
+		if err != nil {
+			if errors.Is(err, db.ErrUnknownAssetType) {
+				return fmt.Errorf("%w: item %d: %w", errInvalidKit, i+1, err)
+			}
+			return err
+		}
+
+		// minimum specs are a partial spec document of the asset type
+		if _, err := utils.ValidateSpecs(def.Fields, item.MinSpecs, true); err != nil {
+			return fmt.Errorf("%w: item %d: %w", errInvalidKit, i+1, err)
+		}
+	}
+	return nil
+}
+
+func ListKits(w http.ResponseWriter, r *http.Request) {
+	var types []string
+	for _, v := range strings.Split(r.URL.Query().Get("user_type"), ",") {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			types = append(types, trimmed)
+		}
+	}
+
+	kits, err := db.ListKits(types)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list kits", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(kits)
+}
+
+func GetKit(w http.ResponseWriter, r *http.Request) {
+	kitID := chi.URLParam(r, "kit_id")
+	if !utils.IsValidUUID(kitID) {
+		http.Error(w, "kit not found", http.StatusNotFound)
+		return
+	}
+
+	kit, err := db.GetKit(kitID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch kit", http.StatusInternalServerError)
+		return
+	}
+	if kit == nil {
+		http.Error(w, "kit not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(kit)
+}
+
+func CreateKit(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateKitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	if !slices.Contains(userTypes, req.UserType) {
+		http.Error(w, "user_type must be full_time, intern or freelancer", http.StatusBadRequest)
+		return
+	}
+	if err := validateKitItems(req.Items); err != nil {
+		if errors.Is(err, errInvalidKit) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to validate kit items", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	taken, err := db.IsKitNameTaken(tx, req.Name, "")
+	if err != nil {
+		http.Error(w, "failed to check kit name", http.StatusInternalServerError)
+		return
+	}
+	if taken {
+		http.Error(w, "a kit with this name already exists", http.StatusConflict)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	kitID, err := db.CreateKit(tx, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to create kit", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Kit created successfully",
+		"kit_id":  kitID,
+	})
+}
+
+func UpdateKit(w http.ResponseWriter, r *http.Request) {
+	kitID := chi.URLParam(r, "kit_id")
+	if !utils.IsValidUUID(kitID) {
+		http.Error(w, "kit not found", http.StatusNotFound)
+		return
+	}
+
+	var req models.UpdateKitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+		if *req.Name == "" {
+			http.Error(w, "name cannot be empty", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.UserType != nil && !slices.Contains(userTypes, *req.UserType) {
+		http.Error(w, "user_type must be full_time, intern or freelancer", http.StatusBadRequest)
+		return
+	}
+	if req.Items != nil {
+		if err := validateKitItems(req.Items); err != nil {
+			if errors.Is(err, errInvalidKit) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Println(err.Error())
+			http.Error(w, "failed to validate kit items", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	if req.Name != nil {
+		taken, err := db.IsKitNameTaken(tx, *req.Name, kitID)
+		if err != nil {
+			http.Error(w, "failed to check kit name", http.StatusInternalServerError)
+			return
+		}
+		if taken {
+			http.Error(w, "a kit with this name already exists", http.StatusConflict)
+			return
+		}
+	}
+
+	authUserID := middleware.GetUserID(r)
+	updated, err := db.UpdateKit(tx, kitID, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update kit", http.StatusInternalServerError)
+		return
+	}
+	if updated == 0 {
+		http.Error(w, "kit not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Kit updated successfully",
+	})
+}
+
+func DeleteKit(w http.ResponseWriter, r *http.Request) {
+	kitID := chi.URLParam(r, "kit_id")
+	if !utils.IsValidUUID(kitID) {
+		http.Error(w, "kit not found", http.StatusNotFound)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	archived, err := db.ArchiveKit(kitID, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to delete kit", http.StatusInternalServerError)
+		return
+	}
+	if archived == 0 {
+		http.Error(w, "kit not found", http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte("kit deleted successfully"))
+}
+
+// AssignKit picks available assets for every line of the user's kit and assigns them together.
+// Without kit_id the kit of the user's user_type is used. With ?dry_run=true the picks are only shown.
+func AssignKit(w http.ResponseWriter, r *http.Request) {
+	var req models.AssignKitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !utils.IsValidUUID(req.UserID) {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	user, err := db.GetUserDetailsByUserID(req.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "error in finding user", http.StatusInternalServerError)
+		return
+	}
+
+	kit, err := resolveKit(req.KitID, user.UserType)
+	if err != nil {
+		if errors.Is(err, errInvalidKit) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to find a kit for the user", http.StatusInternalServerError)
+		return
+	}
+	if kit == nil {
+		http.Error(w, "kit not found", http.StatusNotFound)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "could not begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	err = db.IsUserExistByID(req.UserID, tx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "error in finding user", http.StatusInternalServerError)
+		return
+	}
+
+	result := models.KitAssignmentResult{
+		KitID:   kit.ID,
+		KitName: kit.Name,
+		UserID:  req.UserID,
+		DryRun:  r.URL.Query().Get("dry_run") == "true",
+	}
+
+	picked := []string{}
+	failed := false
+	for i := range kit.Items {
+		item := kit.Items[i]
+		line := models.KitLineResult{KitItem: item, AssetIDs: []string{}}
+
+		def, err := db.GetAssetType(item.AssetType)
+		if err != nil {
+			line.Error = err.Error()
+		} else {
+			var assetIDs []string
+			assetIDs, err = db.PickAvailableAssets(tx, &item, def.Fields, picked)
+			if err != nil {
+				log.Println(err.Error())
+				http.Error(w, "failed to pick assets", http.StatusInternalServerError)
+				return
+			}
+			if len(assetIDs) < item.Quantity {
+				line.Error = fmt.Sprintf("only %d of %d matching assets available", len(assetIDs), item.Quantity)
+			}
+			line.AssetIDs = append(line.AssetIDs, assetIDs...)
+			picked = append(picked, assetIDs...)
+		}
+
+		failed = failed || line.Error != ""
+		result.Lines = append(result.Lines, line)
+	}
+
+	if failed {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+	if result.DryRun {
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	for _, assetID := range picked {
+		if err = assignAssetToUser(tx, &models.AssignAssetRequest{AssetID: assetID, UserID: req.UserID}); err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to assign asset "+assetID, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	result.Applied = true
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// resolveKit loads the requested kit, or the only kit for the user type when none is given.
+// A kit_id that does not exist gives a nil kit.
+func resolveKit(kitID *string, userType string) (*models.OnboardingKit, error) {
+	if kitID != nil {
+		if !utils.IsValidUUID(*kitID) {
+			return nil, fmt.Errorf("%w: kit_id is not a valid id", errInvalidKit)
+		}
+		return db.GetKit(*kitID)
+	}
+
+	kits, err := db.ListKits([]string{userType})
+	if err != nil {
+		return nil, err
+	}
+	switch len(kits) {
+	case 0:
+		return nil, fmt.Errorf("%w: no kit is defined for user type %s", errInvalidKit, userType)
+	case 1:
+		return &kits[0], nil
+	default:
+		return nil, fmt.Errorf("%w: several kits exist for user type %s, pass kit_id", errInvalidKit, userType)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/labels"
+	"storex/models"
+	"storex/utils"
+	"strings"
+	"time"
+)
+
+// maxLabels caps one print run at ten A4 sheets
+const maxLabels = 210
+
+// PrintLabels renders label sheets for the assets picked by ?id= or the usual listing filters.
+// ?format=pdf|png, ?code=qr|code128 and ?encode=id|serial, short serials suit Code128 best.
+func PrintLabels(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+	if format != "pdf" && format != "png" {
+		http.Error(w, "format must be pdf or png", http.StatusBadRequest)
+		return
+	}
+
+	opts := labels.Options{
+		Symbology: r.URL.Query().Get("code"),
+		Encode:    r.URL.Query().Get("encode"),
+	}
+	if err := opts.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params, ok := parseAssetFilters(w, r)
+	if !ok {
+		return
+	}
+	params.Limit = maxLabels + 1
+	assets, err := db.ListAssets(&params)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list assets", http.StatusInternalServerError)
+		return
+	}
+	if len(assets) == 0 {
+		http.Error(w, "no assets found", http.StatusNotFound)
+		return
+	}
+	if len(assets) > maxLabels {
+		http.Error(w, "at most 210 labels can be printed at once, narrow the filters", http.StatusBadRequest)
+		return
+	}
+
+	var buf bytes.Buffer
+	if format == "png" {
+		err = labels.RenderPNG(&buf, assets, opts)
+	} else {
+		err = labels.RenderPDF(&buf, assets, opts)
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to render labels", http.StatusInternalServerError)
+		return
+	}
+
+	contentType := "application/pdf"
+	if format == "png" {
+		contentType = "image/png"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=labels-%s.%s", time.Now().Format("20060102"), format))
+	w.Write(buf.Bytes())
+}
+
+// ScanAsset resolves a scanned label, the asset id or its serial number, to the asset detail
+func ScanAsset(w http.ResponseWriter, r *http.Request) {
+	code := strings.TrimSpace(r.URL.Query().Get("code"))
+	if code == "" {
+		http.Error(w, "code is required", http.StatusBadRequest)
+		return
+	}
+
+	assets, err := findAssetsByCode(code)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to look up asset", http.StatusInternalServerError)
+		return
+	}
+
+	switch len(assets) {
+	case 0:
+		http.Error(w, "no asset matches the scanned code", http.StatusNotFound)
+	case 1:
+		writeAssetDetail(w, assets[0].ID)
+	default:
+		// serials that differ only in case, let the caller pick
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(assets)
+	}
+}
+
+// findAssetsByCode matches a scanned code against asset ids, or serial numbers ignoring case
+func findAssetsByCode(code string) ([]models.ListAssetsResponse, error) {
+	params := models.ListAssetsQueryParams{IncludeDisposed: true, ListParams: models.ListParams{Limit: 10}}
+	if utils.IsValidUUID(code) {
+		params.IDs = []string{code}
+	} else {
+		params.SerialNo = code
+	}
+	return db.ListAssets(&params)
+}
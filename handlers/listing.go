@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"storex/models"
+	"storex/utils"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultListLimit = 10
+	maxListLimit     = 100
+)
+
+// parseListParams reads limit, sort (e.g. sort=purchased_date:desc,serial_no) and either a cursor
+// or a page. On a bad value the error is written and ok is false.
+func parseListParams(w http.ResponseWriter, r *http.Request, fields []models.SortField) (list models.ListParams, ok bool) {
+	query := r.URL.Query()
+
+	list.Limit = defaultListLimit
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "limit is not a number", http.StatusBadRequest)
+			return list, false
+		}
+		if limit >= 1 && limit <= maxListLimit {
+			list.Limit = limit
+		}
+	}
+
+	for _, v := range strings.Split(query.Get("sort"), ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		name, dir, _ := strings.Cut(v, ":")
+		idx := slices.IndexFunc(fields, func(f models.SortField) bool { return f.Name == name })
+		if idx < 0 {
+			http.Error(w, "cannot sort by "+name+", sort fields are "+sortFieldNames(fields), http.StatusBadRequest)
+			return list, false
+		}
+		if dir != "" && dir != "asc" && dir != "desc" {
+			http.Error(w, "sort direction must be asc or desc", http.StatusBadRequest)
+			return list, false
+		}
+		if slices.ContainsFunc(list.Sort, func(k models.SortKey) bool { return k.Name == name }) {
+			http.Error(w, name+" is sorted on twice", http.StatusBadRequest)
+			return list, false
+		}
+		list.Sort = append(list.Sort, models.SortKey{SortField: fields[idx], Desc: dir == "desc"})
+	}
+
+	cursor, page := query.Get("cursor"), query.Get("page")
+	switch {
+	case cursor != "" && page != "":
+		http.Error(w, "use either cursor or page", http.StatusBadRequest)
+		return list, false
+
+	case cursor != "":
+		values, backward, err := utils.DecodeCursor(cursor)
+		// a cursor holds the sort values and the id, it only fits the sort it was made with.
+		// Without a sort the listing falls back to a single default key.
+		if err != nil || len(values) != max(len(list.Sort), 1)+1 {
+			http.Error(w, "invalid cursor, start again from the first page", http.StatusBadRequest)
+			return list, false
+		}
+		list.After, list.Backward = values, backward
+
+	case page != "":
+		n, err := strconv.Atoi(page)
+		if err != nil {
+			http.Error(w, "page is not a number", http.StatusBadRequest)
+			return list, false
+		}
+		list.Page = max(n, 1)
+		list.Offset = (list.Page - 1) * list.Limit
+	}
+
+	return list, true
+}
+
+// parseFacets reads which facets to count, facets=true asks for every one.
+// On an unknown facet the error is written and ok is false.
+func parseFacets(w http.ResponseWriter, r *http.Request, fields []models.FacetField) (facets []models.FacetField, ok bool) {
+	v := r.URL.Query().Get("facets")
+	switch v {
+	case "", "false":
+		return nil, true
+	case "true":
+		return fields, true
+	}
+
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		idx := slices.IndexFunc(fields, func(f models.FacetField) bool { return f.Name == name })
+		if idx < 0 {
+			names := make([]string, len(fields))
+			for i, f := range fields {
+				names[i] = f.Name
+			}
+			http.Error(w, "cannot count by "+name+", facets are "+strings.Join(names, ", "), http.StatusBadRequest)
+			return nil, false
+		}
+		if !slices.ContainsFunc(facets, func(f models.FacetField) bool { return f.Name == name }) {
+			facets = append(facets, fields[idx])
+		}
+	}
+	return facets, true
+}
+
+func sortFieldNames(fields []models.SortField) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// writeListPage writes the envelope of a listing. The rows were fetched with one extra row past
+// the limit, which only tells whether the listing goes on in the direction walked.
+func writeListPage[T any](w http.ResponseWriter, r *http.Request, list models.ListParams, rows []T, total int,
+	facets map[string][]models.FacetCount, cursor func(T) []string) {
+	more := len(rows) > list.Limit
+	if more {
+		if list.Backward {
+			rows = rows[len(rows)-list.Limit:]
+		} else {
+			rows = rows[:list.Limit]
+		}
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+
+	link := func(set func(q map[string][]string)) *string {
+		u := *r.URL
+		q := u.Query()
+		q.Del("cursor")
+		q.Del("page")
+		set(q)
+		u.RawQuery = q.Encode()
+		s := u.String()
+		return &s
+	}
+	pageLink := func(n int) *string {
+		return link(func(q map[string][]string) { q["page"] = []string{strconv.Itoa(n)} })
+	}
+	cursorLink := func(row T, backward bool) *string {
+		return link(func(q map[string][]string) { q["cursor"] = []string{utils.EncodeCursor(cursor(row), backward)} })
+	}
+
+	var links models.PageLinks
+	switch {
+	case list.Page > 0:
+		if list.Offset+len(rows) < total {
+			links.Next = pageLink(list.Page + 1)
+		}
+		if list.Page > 1 {
+			links.Prev = pageLink(list.Page - 1)
+		}
+	case len(rows) > 0:
+		// walking forward there is more ahead when the extra row came back and something behind once
+		// a cursor was followed, walking backward it is the other way round
+		if more || list.Backward {
+			links.Next = cursorLink(rows[len(rows)-1], false)
+		}
+		if (more && list.Backward) || (!list.Backward && len(list.After) > 0) {
+			links.Prev = cursorLink(rows[0], true)
+		}
+	}
+
+	json.NewEncoder(w).Encode(models.ListPage{
+		Items: rows,
+		Total: total,
+		Limit: list.Limit,
+		Links: links,
+
+		Facets: facets,
+	})
+}
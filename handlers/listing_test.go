@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"storex/models"
+	"storex/utils"
+	"testing"
+)
+
+var testSortFields = []models.SortField{
+	{Name: "serial_no", Column: "a.serial_no", Type: "TEXT"},
+	{Name: "purchased_date", Column: "a.purchased_date", Type: "DATE"},
+}
+
+func parseListQuery(query url.Values) (models.ListParams, int, bool) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/assets?"+query.Encode(), nil)
+	list, ok := parseListParams(w, r, testSortFields)
+	return list, w.Code, ok
+}
+
+func TestParseListParams(t *testing.T) {
+	list, _, ok := parseListQuery(url.Values{"sort": {"purchased_date:desc, serial_no"}, "limit": {"25"}, "page": {"3"}})
+	if !ok {
+		t.Fatal("valid query rejected")
+	}
+	wantSort := []models.SortKey{{SortField: testSortFields[1], Desc: true}, {SortField: testSortFields[0]}}
+	if !reflect.DeepEqual(list.Sort, wantSort) {
+		t.Errorf("got sort %+v, want %+v", list.Sort, wantSort)
+	}
+	if list.Limit != 25 || list.Page != 3 || list.Offset != 50 {
+		t.Errorf("got limit %d, page %d, offset %d", list.Limit, list.Page, list.Offset)
+	}
+
+	// out of range limits fall back to the default, pages start at 1
+	list, _, _ = parseListQuery(url.Values{"limit": {"1000"}, "page": {"-2"}})
+	if list.Limit != defaultListLimit || list.Page != 1 || list.Offset != 0 {
+		t.Errorf("got limit %d, page %d, offset %d", list.Limit, list.Page, list.Offset)
+	}
+}
+
+func TestParseListParamsCursor(t *testing.T) {
+	id := "8c5d6f1e-3b1a-4c7e-9f0a-1d2e3f4a5b6c"
+
+	list, _, ok := parseListQuery(url.Values{
+		"sort":   {"serial_no,purchased_date"},
+		"cursor": {utils.EncodeCursor([]string{"SN-1", "2024-01-15", id}, true)},
+	})
+	if !ok {
+		t.Fatal("valid cursor rejected")
+	}
+	if !reflect.DeepEqual(list.After, []string{"SN-1", "2024-01-15", id}) || !list.Backward {
+		t.Errorf("got after %v, backward %v", list.After, list.Backward)
+	}
+
+	// without a sort the cursor holds the default key and the id
+	if _, _, ok := parseListQuery(url.Values{"cursor": {utils.EncodeCursor([]string{"2024-01-15", id}, false)}}); !ok {
+		t.Error("cursor of the default sort rejected")
+	}
+}
+
+func TestParseListParamsRejects(t *testing.T) {
+	id := "8c5d6f1e-3b1a-4c7e-9f0a-1d2e3f4a5b6c"
+
+	tests := []struct {
+		name  string
+		query url.Values
+	}{
+		{"limit not a number", url.Values{"limit": {"ten"}}},
+		{"page not a number", url.Values{"page": {"two"}}},
+		{"unknown sort field", url.Values{"sort": {"price"}}},
+		{"bad sort direction", url.Values{"sort": {"serial_no:up"}}},
+		{"sorted twice", url.Values{"sort": {"serial_no,serial_no:desc"}}},
+		{"cursor and page", url.Values{"cursor": {utils.EncodeCursor([]string{"x", id}, false)}, "page": {"2"}}},
+		{"garbled cursor", url.Values{"cursor": {"%%%"}}},
+		// a cursor made under another sort has a different number of values
+		{"cursor too short", url.Values{"sort": {"serial_no,purchased_date"}, "cursor": {utils.EncodeCursor([]string{"SN-1", id}, false)}}},
+		{"cursor too long", url.Values{"cursor": {utils.EncodeCursor([]string{"SN-1", "2024-01-15", id}, false)}}},
+	}
+	for _, tt := range tests {
+		_, code, ok := parseListQuery(tt.query)
+		if ok || code != http.StatusBadRequest {
+			t.Errorf("%s: got ok %v and status %d, want a 400", tt.name, ok, code)
+		}
+	}
+}
+
+func TestParseFacets(t *testing.T) {
+	fields := []models.FacetField{{Name: "status"}, {Name: "brand"}}
+	parse := func(v string) ([]models.FacetField, bool) {
+		r := httptest.NewRequest(http.MethodGet, "/assets?"+url.Values{"facets": {v}}.Encode(), nil)
+		return parseFacets(httptest.NewRecorder(), r, fields)
+	}
+
+	if facets, ok := parse("false"); !ok || facets != nil {
+		t.Errorf("facets=false got %v, %v", facets, ok)
+	}
+	if facets, ok := parse("true"); !ok || !reflect.DeepEqual(facets, fields) {
+		t.Errorf("facets=true got %v, %v", facets, ok)
+	}
+	if facets, ok := parse("brand, brand,status"); !ok || !reflect.DeepEqual(facets, []models.FacetField{fields[1], fields[0]}) {
+		t.Errorf("got %v, %v", facets, ok)
+	}
+	if _, ok := parse("colour"); ok {
+		t.Error("unknown facet accepted")
+	}
+}
+
+type testListPage struct {
+	Items []string         `json:"items"`
+	Total int              `json:"total"`
+	Links models.PageLinks `json:"links"`
+}
+
+func writeTestListPage(t *testing.T, target string, list models.ListParams, rows []string, total int) testListPage {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	writeListPage(w, r, list, rows, total, nil, func(row string) []string { return []string{row, "id-" + row} })
+
+	var page testListPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func linkCursor(t *testing.T, link *string) ([]string, bool) {
+	t.Helper()
+	if link == nil {
+		t.Fatal("link missing")
+	}
+	u, err := url.Parse(*link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, backward, err := utils.DecodeCursor(u.Query().Get("cursor"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values, backward
+}
+
+func TestWriteListPageCursor(t *testing.T) {
+	// the first page, the extra row tells there is more
+	page := writeTestListPage(t, "/assets?sort=serial_no", models.ListParams{Limit: 2}, []string{"a", "b", "c"}, 5)
+	if !reflect.DeepEqual(page.Items, []string{"a", "b"}) || page.Links.Prev != nil {
+		t.Errorf("got items %v and prev %v", page.Items, page.Links.Prev)
+	}
+	if values, backward := linkCursor(t, page.Links.Next); !reflect.DeepEqual(values, []string{"b", "id-b"}) || backward {
+		t.Errorf("next cursor got %v, backward %v", values, backward)
+	}
+
+	// walking backward the rows are already back in order, the extra row is in front
+	list := models.ListParams{Limit: 2, After: []string{"d", "id-d"}, Backward: true}
+	page = writeTestListPage(t, "/assets?sort=serial_no&cursor=x", list, []string{"a", "b", "c"}, 5)
+	if !reflect.DeepEqual(page.Items, []string{"b", "c"}) {
+		t.Errorf("got items %v", page.Items)
+	}
+	if values, backward := linkCursor(t, page.Links.Prev); !reflect.DeepEqual(values, []string{"b", "id-b"}) || !backward {
+		t.Errorf("prev cursor got %v, backward %v", values, backward)
+	}
+	if values, backward := linkCursor(t, page.Links.Next); !reflect.DeepEqual(values, []string{"c", "id-c"}) || backward {
+		t.Errorf("next cursor got %v, backward %v", values, backward)
+	}
+
+	// the last page going forward
+	list = models.ListParams{Limit: 2, After: []string{"c", "id-c"}}
+	page = writeTestListPage(t, "/assets", list, []string{"d"}, 5)
+	if page.Links.Next != nil || page.Links.Prev == nil {
+		t.Errorf("got next %v and prev %v", page.Links.Next, page.Links.Prev)
+	}
+}
+
+func TestWriteListPagePages(t *testing.T) {
+	page := writeTestListPage(t, "/assets?page=2&limit=2", models.ListParams{Limit: 2, Page: 2, Offset: 2}, []string{"c", "d", "e"}, 5)
+	if !reflect.DeepEqual(page.Items, []string{"c", "d"}) || page.Total != 5 {
+		t.Errorf("got items %v and total %d", page.Items, page.Total)
+	}
+	if page.Links.Next == nil || page.Links.Prev == nil {
+		t.Fatalf("got next %v and prev %v", page.Links.Next, page.Links.Prev)
+	}
+	if u, _ := url.Parse(*page.Links.Next); u.Query().Get("page") != "3" || u.Query().Get("limit") != "2" {
+		t.Errorf("got next %s", *page.Links.Next)
+	}
+
+	page = writeTestListPage(t, "/assets?page=9", models.ListParams{Limit: 2, Page: 9, Offset: 16}, nil, 5)
+	if page.Items == nil || len(page.Items) != 0 || page.Links.Next != nil {
+		t.Errorf("past the end got items %v and next %v", page.Items, page.Links.Next)
+	}
+}
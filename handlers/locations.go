@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"slices"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strings"
+)
+
+// locationKinds in hierarchy order, a location sits below one of an earlier kind
+var locationKinds = []string{"office", "floor", "room", "cabinet"}
+
+// validLocation checks a location named by a status change, nil is left to the default.
+// It answers the request itself when the location is unusable.
+func validLocation(w http.ResponseWriter, locationID *string) bool {
+	if locationID == nil {
+		return true
+	}
+	if !utils.IsValidUUID(*locationID) {
+		http.Error(w, "location_id is not a valid id", http.StatusBadRequest)
+		return false
+	}
+
+	loc, err := db.GetLocation(*locationID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch location", http.StatusInternalServerError)
+		return false
+	}
+	if loc == nil {
+		http.Error(w, "location not found", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func ListLocations(w http.ResponseWriter, r *http.Request) {
+	var kinds []string
+	for _, v := range strings.Split(r.URL.Query().Get("kind"), ",") {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			kinds = append(kinds, trimmed)
+		}
+	}
+
+	locations, err := db.ListLocations(kinds)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list locations", http.StatusInternalServerError)
+		return
+	}
+	if len(locations) == 0 {
+		http.Error(w, "no locations found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(locations)
+}
+
+func GetLocation(w http.ResponseWriter, r *http.Request) {
+	loc, err := db.GetLocation(chi.URLParam(r, "location_id"))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch location", http.StatusInternalServerError)
+		return
+	}
+	if loc == nil {
+		http.Error(w, "location not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(loc)
+}
+
+// CreateLocation adds an office, or a floor, room or cabinet below an existing location
+func CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	rank := slices.Index(locationKinds, req.Kind)
+	if rank < 0 {
+		http.Error(w, "kind must be office, floor, room or cabinet", http.StatusBadRequest)
+		return
+	}
+
+	if req.Kind == "office" {
+		if req.ParentID != nil {
+			http.Error(w, "offices cannot sit below another location", http.StatusBadRequest)
+			return
+		}
+		if req.City == nil || strings.TrimSpace(*req.City) == "" {
+			http.Error(w, "city is required for an office", http.StatusBadRequest)
+			return
+		}
+		*req.City = strings.TrimSpace(*req.City)
+	} else {
+		if req.City != nil {
+			http.Error(w, "city is only set on offices", http.StatusBadRequest)
+			return
+		}
+		if req.ParentID == nil || !utils.IsValidUUID(*req.ParentID) {
+			http.Error(w, "parent_id is required for a "+req.Kind, http.StatusBadRequest)
+			return
+		}
+
+		parent, err := db.GetLocation(*req.ParentID)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to fetch parent location", http.StatusInternalServerError)
+			return
+		}
+		if parent == nil {
+			http.Error(w, "parent location not found", http.StatusBadRequest)
+			return
+		}
+		if parentRank := slices.Index(locationKinds, parent.Kind); parentRank < 0 || parentRank >= rank {
+			http.Error(w, "a "+req.Kind+" cannot sit below a "+parent.Kind, http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	taken, err := db.IsLocationNameTaken(tx, req.ParentID, req.Name, "")
+	if err != nil {
+		http.Error(w, "failed to check location name", http.StatusInternalServerError)
+		return
+	}
+	if taken {
+		http.Error(w, "a location with this name already exists there", http.StatusConflict)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	locationID, err := db.CreateLocation(tx, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to create location", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":     "Location created successfully",
+		"location_id": locationID,
+	})
+}
+
+// UpdateLocation renames a location or changes the city of an office
+func UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	locationID := chi.URLParam(r, "location_id")
+
+	var req models.UpdateLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	loc, err := db.GetLocation(locationID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch location", http.StatusInternalServerError)
+		return
+	}
+	if loc == nil {
+		http.Error(w, "location not found", http.StatusNotFound)
+		return
+	}
+
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+		if *req.Name == "" {
+			http.Error(w, "name cannot be empty", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.City != nil {
+		*req.City = strings.TrimSpace(*req.City)
+		if loc.Kind != "office" {
+			http.Error(w, "city is only set on offices", http.StatusBadRequest)
+			return
+		}
+		if *req.City == "" {
+			http.Error(w, "city cannot be empty", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	if req.Name != nil {
+		taken, err := db.IsLocationNameTaken(tx, loc.ParentID, *req.Name, locationID)
+		if err != nil {
+			http.Error(w, "failed to check location name", http.StatusInternalServerError)
+			return
+		}
+		if taken {
+			http.Error(w, "a location with this name already exists there", http.StatusConflict)
+			return
+		}
+	}
+
+	authUserID := middleware.GetUserID(r)
+	updated, err := db.UpdateLocation(tx, locationID, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update location", http.StatusInternalServerError)
+		return
+	}
+	if updated == 0 {
+		http.Error(w, "location not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Location updated successfully",
+	})
+}
+
+// DeleteLocation archives an empty location, one holding assets or other locations is refused
+func DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	locationID := chi.URLParam(r, "location_id")
+	if !utils.IsValidUUID(locationID) {
+		http.Error(w, "location not found", http.StatusNotFound)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	children, assets, err := db.CountLocationUsage(tx, locationID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to check location usage", http.StatusInternalServerError)
+		return
+	}
+	if children > 0 || assets > 0 {
+		http.Error(w, "location still holds other locations or assets", http.StatusConflict)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	archived, err := db.ArchiveLocation(tx, locationID, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to delete location", http.StatusInternalServerError)
+		return
+	}
+	if archived == 0 {
+		// the remote location is needed by every assignment
+		http.Error(w, "location not found or cannot be deleted", http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte("location deleted successfully"))
+}
+
+// OfficeStock lists the available assets per office by asset type, for deciding where to ship from
+func OfficeStock(w http.ResponseWriter, r *http.Request) {
+	stock, err := db.ListOfficeStock()
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to count office stock", http.StatusInternalServerError)
+		return
+	}
+	if len(stock) == 0 {
+		http.Error(w, "no offices found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(stock)
+}
+
+// MoveAsset records that an asset changed place without changing status, such as stock moved between offices
+func MoveAsset(w http.ResponseWriter, r *http.Request) {
+	assetID := chi.URLParam(r, "asset_id")
+
+	var req models.MoveAssetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.LocationID == "" {
+		http.Error(w, "location_id is required", http.StatusBadRequest)
+		return
+	}
+	if !validLocation(w, &req.LocationID) {
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "could not begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	current, err := db.GetActiveAssetStatus(tx, assetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+		return
+	}
+	if current == nil {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+	if current.Status == "disposed" {
+		http.Error(w, "disposed assets cannot be moved", http.StatusBadRequest)
+		return
+	}
+
+	if err = db.SetActiveStatusLocation(tx, assetID, req.LocationID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to move asset", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":     "Asset moved successfully",
+		"asset_id":    assetID,
+		"location_id": req.LocationID,
+	})
+}
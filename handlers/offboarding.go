@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/notifier"
+	"storex/utils"
+	"strings"
+	"time"
+)
+
+// Notifier delivers notices raised by requests, main replaces it with the configured one
+var Notifier notifier.Notifier = notifier.LogNotifier{}
+
+var errOffboardingExists = errors.New("user is already being offboarded")
+
+// notifyAssetManagers sends a notice to every admin and asset manager, failures are only logged
+func notifyAssetManagers(subject string, body string) {
+	recipients, err := db.ListUserEmailsByRoles([]string{"admin", "asset_manager"})
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	if len(recipients) == 0 {
+		log.Printf("no admin or asset manager to notify about %q", subject)
+		return
+	}
+
+	msg := notifier.Message{To: recipients, Subject: subject, Body: body}
+	if err := Notifier.Notify(context.Background(), msg); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// OpenOffboarding starts the exit process of a user with a checklist of every asset they hold.
+// A user holding nothing is archived right away.
+func OpenOffboarding(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateOffboardingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !utils.IsValidUUID(req.UserID) {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	lastWorkingDate, err := time.Parse("2006-01-02", req.LastWorkingDate)
+	if err != nil {
+		http.Error(w, "last_working_date must be a date like 2024-01-31", http.StatusBadRequest)
+		return
+	}
+
+	user, err := db.GetUserDetailsByUserID(req.UserID)
+	if err == nil {
+		err = db.GetAllAssetsByUser(req.UserID, &user)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch user assets", http.StatusInternalServerError)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	offboardingID, err := openOffboarding(&user, lastWorkingDate, authUserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "user not found", http.StatusNotFound)
+		case errors.Is(err, errOffboardingExists):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Println(err.Error())
+			http.Error(w, "failed to open offboarding", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	status := "completed"
+	if len(user.AssignedAssets) > 0 {
+		status = "open"
+		notifyAssetManagers(offboardingMessage(&user, lastWorkingDate))
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":        "Offboarding opened successfully",
+		"offboarding_id": offboardingID,
+		"status":         status,
+	})
+}
+
+// openOffboarding records the offboarding and its checklist in one transaction
+func openOffboarding(user *models.UserDetails, lastWorkingDate time.Time, authUserID string) (offboardingID string, err error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	if err = db.IsUserExistByID(user.ID, tx); err != nil {
+		return "", err
+	}
+
+	open, err := db.HasOpenOffboarding(tx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	if open {
+		err = errOffboardingExists
+		return "", err
+	}
+
+	offboardingID, err = db.CreateOffboarding(tx, user.ID, lastWorkingDate, authUserID)
+	if err != nil {
+		return "", err
+	}
+	for _, asset := range user.AssignedAssets {
+		if err = db.InsertOffboardingItem(tx, offboardingID, asset.AssetID); err != nil {
+			return "", err
+		}
+	}
+
+	// nothing to collect, the user can leave now
+	if len(user.AssignedAssets) == 0 {
+		if err = db.SoftDeleteUser(tx, user.ID, authUserID); err == nil {
+			err = db.CloseOffboarding(tx, offboardingID, "completed", authUserID)
+		}
+	}
+	return offboardingID, err
+}
+
+func offboardingMessage(user *models.UserDetails, lastWorkingDate time.Time) (string, string) {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s (%s) leaves on %s and holds %d asset(s) to collect:\n\n",
+		user.Name, user.Email, lastWorkingDate.Format("2006-01-02"), len(user.AssignedAssets))
+	for _, a := range user.AssignedAssets {
+		fmt.Fprintf(&body, "- %s %s (%s), %s since %s\n",
+			a.BrandName, a.ModelName, a.AssetID, a.Status, a.AssignedAt.Format("2006-01-02"))
+	}
+
+	return fmt.Sprintf("Storex: offboarding of %s", user.Name), body.String()
+}
+
+func ListOffboardings(w http.ResponseWriter, r *http.Request) {
+	var status []string
+	for _, v := range strings.Split(r.URL.Query().Get("status"), ",") {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			status = append(status, trimmed)
+		}
+	}
+
+	offboardings, err := db.ListOffboardings(status)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list offboardings", http.StatusInternalServerError)
+		return
+	}
+	if len(offboardings) == 0 {
+		http.Error(w, "no offboardings found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(offboardings)
+}
+
+func GetOffboarding(w http.ResponseWriter, r *http.Request) {
+	offboardingID := chi.URLParam(r, "offboarding_id")
+	if !utils.IsValidUUID(offboardingID) {
+		http.Error(w, "offboarding not found", http.StatusNotFound)
+		return
+	}
+
+	offboarding, err := db.GetOffboarding(offboardingID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch offboarding", http.StatusInternalServerError)
+		return
+	}
+	if offboarding == nil {
+		http.Error(w, "offboarding not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(offboarding)
+}
+
+// ClearOffboardingItem ticks an asset off the checklist as returned or lost.
+// An asset still with the user goes back to the pool or is written off, once nothing is pending the user is archived.
+func ClearOffboardingItem(w http.ResponseWriter, r *http.Request) {
+	offboardingID := chi.URLParam(r, "offboarding_id")
+	assetID := chi.URLParam(r, "asset_id")
+	if !utils.IsValidUUID(offboardingID) || !utils.IsValidUUID(assetID) {
+		http.Error(w, "offboarding item not found", http.StatusNotFound)
+		return
+	}
+
+	var req models.ClearOffboardingItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Status != "returned" && req.Status != "lost" {
+		http.Error(w, "status must be returned or lost", http.StatusBadRequest)
+		return
+	}
+	if !validLocation(w, req.LocationID) {
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	offboarding, err := db.LockOffboarding(tx, offboardingID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch offboarding", http.StatusInternalServerError)
+		return
+	}
+	if offboarding == nil {
+		http.Error(w, "offboarding not found", http.StatusNotFound)
+		return
+	}
+	if offboarding.Status != "open" {
+		http.Error(w, "offboarding is already "+offboarding.Status, http.StatusBadRequest)
+		return
+	}
+
+	itemStatus, err := db.GetOffboardingItemStatus(tx, offboardingID, assetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch checklist item", http.StatusInternalServerError)
+		return
+	}
+	if itemStatus == "" {
+		http.Error(w, "asset is not on the checklist", http.StatusNotFound)
+		return
+	}
+	if itemStatus != "pending" {
+		http.Error(w, "asset is already marked "+itemStatus, http.StatusBadRequest)
+		return
+	}
+
+	// assets already retrieved or reported elsewhere are only ticked off
+	current, err := db.GetActiveAssetStatus(tx, assetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	if current != nil && current.AssignedToUser != nil && *current.AssignedToUser == offboarding.UserID {
+		if err = db.ArchiveAssetStatus(tx, current.ID); err != nil {
+			http.Error(w, "failed to archive asset status", http.StatusInternalServerError)
+			return
+		}
+
+		remarks := "collected at offboarding"
+		if req.Remarks != nil {
+			remarks = *req.Remarks
+		}
+		if req.Status == "returned" {
+			err = db.InsertAssetStatusWithRemarks(tx, assetID, "available", &remarks)
+			if err == nil && req.LocationID != nil {
+				err = db.SetActiveStatusLocation(tx, assetID, *req.LocationID)
+			}
+		} else {
+			_, err = recordDisposal(tx, assetID, &models.DisposeAssetRequest{
+				Reason:     "lost",
+				ApprovedBy: authUserID,
+				Remarks:    &remarks,
+			}, authUserID)
+		}
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to update asset status", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err = db.ClearOffboardingItem(tx, offboardingID, assetID, req.Status, req.Remarks, authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update checklist", http.StatusInternalServerError)
+		return
+	}
+
+	// assets handed to the user after the checklist was made must come back too
+	if _, err = db.AddHeldAssetsToOffboarding(tx, offboardingID, offboarding.UserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update checklist", http.StatusInternalServerError)
+		return
+	}
+
+	pending, err := db.CountPendingOffboardingItems(tx, offboardingID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to count pending items", http.StatusInternalServerError)
+		return
+	}
+
+	// the user must not be archived while still holding anything, whatever the checklist says
+	held := 0
+	if pending == 0 {
+		held, err = db.NumberOfAssetsAssigned(tx, offboarding.UserID)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to count assigned assets", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	status := "open"
+	if pending == 0 && held == 0 {
+		if err = db.SoftDeleteUser(tx, offboarding.UserID, authUserID); err == nil {
+			err = db.CloseOffboarding(tx, offboardingID, "completed", authUserID)
+		}
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to archive user", http.StatusInternalServerError)
+			return
+		}
+		status = "completed"
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"message":        "Checklist updated successfully",
+		"offboarding_id": offboardingID,
+		"status":         status,
+		"pending_items":  pending,
+	})
+}
+
+// CancelOffboarding stops an open offboarding, the user stays active
+func CancelOffboarding(w http.ResponseWriter, r *http.Request) {
+	offboardingID := chi.URLParam(r, "offboarding_id")
+	if !utils.IsValidUUID(offboardingID) {
+		http.Error(w, "offboarding not found", http.StatusNotFound)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	offboarding, err := db.LockOffboarding(tx, offboardingID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch offboarding", http.StatusInternalServerError)
+		return
+	}
+	if offboarding == nil {
+		http.Error(w, "offboarding not found", http.StatusNotFound)
+		return
+	}
+	if offboarding.Status != "open" {
+		http.Error(w, "offboarding is already "+offboarding.Status, http.StatusBadRequest)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	if err = db.CloseOffboarding(tx, offboardingID, "cancelled", authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to cancel offboarding", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte("offboarding cancelled successfully"))
+}
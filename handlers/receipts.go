@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/receipts"
+	"storex/utils"
+	"strings"
+	"time"
+)
+
+// StatusReceipt renders the handover receipt of an assignment row, or with ?type=return the receipt of its return
+func StatusReceipt(w http.ResponseWriter, r *http.Request) {
+	statusID := chi.URLParam(r, "status_id")
+	if !utils.IsValidUUID(statusID) {
+		http.Error(w, "status id is not valid", http.StatusBadRequest)
+		return
+	}
+
+	kind := r.URL.Query().Get("type")
+	if kind == "" {
+		kind = "handover"
+	}
+	if kind != "handover" && kind != "return" {
+		http.Error(w, "type must be handover or return", http.StatusBadRequest)
+		return
+	}
+
+	receipt, err := db.GetStatusReceipt(statusID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch receipt", http.StatusInternalServerError)
+		return
+	}
+	if receipt == nil {
+		http.Error(w, "assignment not found", http.StatusNotFound)
+		return
+	}
+
+	receipt.Kind = kind
+	receipt.Date = receipt.Assets[0].AssignedAt
+	if kind == "return" {
+		if receipt.Assets[0].ReturnedAt == nil {
+			http.Error(w, "asset has not been returned yet", http.StatusBadRequest)
+			return
+		}
+		receipt.Date = *receipt.Assets[0].ReturnedAt
+	}
+
+	writeReceipt(w, receipt, fmt.Sprintf("%s-receipt-%s.pdf", kind, statusID))
+}
+
+// UserStatement renders every asset the user currently holds
+func UserStatement(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "user_id")
+	if !utils.IsValidUUID(userID) {
+		http.Error(w, "user id is not valid", http.StatusBadRequest)
+		return
+	}
+	statement(w, userID)
+}
+
+// MyStatement is the statement of the logged in employee
+func MyStatement(w http.ResponseWriter, r *http.Request) {
+	statement(w, middleware.GetUserID(r))
+}
+
+func statement(w http.ResponseWriter, userID string) {
+	receipt, err := db.GetHeldAssetsReceipt(userID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch held assets", http.StatusInternalServerError)
+		return
+	}
+	if receipt == nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	receipt.Kind = "statement"
+	receipt.Date = time.Now()
+	writeReceipt(w, receipt, fmt.Sprintf("statement-%s-%s.pdf", userID, time.Now().Format("20060102")))
+}
+
+// writeReceipt renders into memory first so a failure can still be reported with a status code
+func writeReceipt(w http.ResponseWriter, receipt *models.Receipt, fileName string) {
+	tpl, err := db.GetReceiptTemplate(receipt.Kind)
+	if err != nil || tpl == nil {
+		if err != nil {
+			log.Println(err.Error())
+		}
+		http.Error(w, "failed to load receipt template", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := receipts.Render(&buf, tpl, receipt); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to render receipt", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "attachment; filename="+fileName)
+	w.Write(buf.Bytes())
+}
+
+func ListReceiptTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := db.ListReceiptTemplates()
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list receipt templates", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(templates)
+}
+
+// UpdateReceiptTemplate changes the organisation wording of a receipt kind, templates are checked before saving
+func UpdateReceiptTemplate(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateReceiptTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tpl, err := db.GetReceiptTemplate(chi.URLParam(r, "kind"))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch receipt template", http.StatusInternalServerError)
+		return
+	}
+	if tpl == nil {
+		http.Error(w, "receipt template not found", http.StatusNotFound)
+		return
+	}
+
+	if req.Organisation != nil {
+		tpl.Organisation = strings.TrimSpace(*req.Organisation)
+	}
+	if req.Title != nil {
+		tpl.Title = *req.Title
+	}
+	if req.Intro != nil {
+		tpl.Intro = *req.Intro
+	}
+	if req.Footer != nil {
+		tpl.Footer = *req.Footer
+	}
+	if tpl.Organisation == "" || strings.TrimSpace(tpl.Title) == "" {
+		http.Error(w, "organisation and title cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if err := receipts.Validate(tpl); err != nil {
+		if errors.Is(err, receipts.ErrInvalidTemplate) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to check receipt template", http.StatusInternalServerError)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	if err := db.UpdateReceiptTemplate(tpl, authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update receipt template", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Receipt template updated successfully",
+		"kind":    tpl.Kind,
+	})
+}
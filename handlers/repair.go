@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"storex/db"
+	"storex/models"
+	"storex/utils"
+)
+
+func MarkAssetDamaged(w http.ResponseWriter, r *http.Request) {
+	changeRepairStatus(w, r, "damaged", []string{"available", "assigned"})
+}
+
+func QueueAssetForRepair(w http.ResponseWriter, r *http.Request) {
+	changeRepairStatus(w, r, "waiting_repair", []string{"available", "assigned", "damaged"})
+}
+
+// changeRepairStatus archives the active status row of the asset and replaces it with newStatus,
+// provided the asset is currently in one of the allowed statuses.
+func changeRepairStatus(w http.ResponseWriter, r *http.Request, newStatus string, allowedFrom []string) {
+	assetID := chi.URLParam(r, "asset_id")
+	if !utils.IsValidUUID(assetID) {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+
+	var req models.AssetStatusChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !validLocation(w, req.LocationID) {
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "could not begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	current, err := db.GetActiveAssetStatus(tx, assetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+		return
+	}
+	if current == nil {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+	if !slices.Contains(allowedFrom, current.Status) {
+		http.Error(w, "asset in status '"+current.Status+"' cannot be moved to '"+newStatus+"'", http.StatusBadRequest)
+		return
+	}
+
+	if err = db.ArchiveAssetStatus(tx, current.ID); err != nil {
+		http.Error(w, "failed to archive asset status", http.StatusInternalServerError)
+		return
+	}
+
+	if err = db.InsertAssetStatusWithRemarks(tx, assetID, newStatus, req.Remarks); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req.LocationID != nil {
+		if err = db.SetActiveStatusLocation(tx, assetID, *req.LocationID); err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to record asset location", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":  "Asset status updated successfully",
+		"asset_id": assetID,
+		"status":   newStatus,
+	})
+}
+
+func SendAssetToService(w http.ResponseWriter, r *http.Request) {
+	var req models.SendToServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if req.AssetID == "" || req.ServiceID == "" {
+		http.Error(w, "asset_id and service_id are required", http.StatusBadRequest)
+		return
+	}
+	if !utils.IsValidUUID(req.AssetID) || !utils.IsValidUUID(req.ServiceID) {
+		http.Error(w, "asset_id and service_id must be valid ids", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "could not begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	err = db.IsServiceExistByID(tx, req.ServiceID)
+	if err != nil {
+		log.Println(err.Error())
+		if err == sql.ErrNoRows {
+			http.Error(w, "service not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "error in finding service", http.StatusInternalServerError)
+		return
+	}
+
+	current, err := db.GetActiveAssetStatus(tx, req.AssetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+		return
+	}
+	if current == nil {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+	if current.Status != "waiting_repair" && current.Status != "damaged" {
+		http.Error(w, "only damaged or waiting_repair assets can be sent to service", http.StatusBadRequest)
+		return
+	}
+
+	if err = db.ArchiveAssetStatus(tx, current.ID); err != nil {
+		http.Error(w, "failed to archive asset status", http.StatusInternalServerError)
+		return
+	}
+
+	if err = db.InsertAssetStatusToService(tx, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte("Asset sent to service successfully"))
+}
+
+func ReceiveAssetFromService(w http.ResponseWriter, r *http.Request) {
+	assetID := chi.URLParam(r, "asset_id")
+	if !utils.IsValidUUID(assetID) {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+
+	var req models.ReceiveFromServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	// repaired assets go back to the pool, unrepairable ones stay damaged
+	var nextStatus string
+	switch req.Outcome {
+	case "repaired":
+		nextStatus = "available"
+	case "unrepairable":
+		nextStatus = "damaged"
+	default:
+		http.Error(w, "outcome must be 'repaired' or 'unrepairable'", http.StatusBadRequest)
+		return
+	}
+	if !validLocation(w, req.LocationID) {
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "could not begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	current, err := db.GetActiveAssetStatus(tx, assetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+		return
+	}
+	if current == nil || current.Status != "service" {
+		http.Error(w, "asset is not at a service vendor", http.StatusBadRequest)
+		return
+	}
+
+	if err = db.CloseServiceStatus(tx, current.ID, req.Outcome); err != nil {
+		http.Error(w, "failed to close service status", http.StatusInternalServerError)
+		return
+	}
+
+	if err = db.InsertAssetStatusWithRemarks(tx, assetID, nextStatus, req.Remarks); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req.LocationID != nil {
+		if err = db.SetActiveStatusLocation(tx, assetID, *req.LocationID); err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to record asset location", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":  "Asset received from service",
+		"asset_id": assetID,
+		"status":   nextStatus,
+	})
+}
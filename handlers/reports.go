@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var assetReportTypes = []string{"damaged", "lost", "stolen", "return"}
+
+// photo limits keep reports small enough to store alongside the data
+const (
+	maxReportPhotos    = 5
+	maxReportPhotoSize = 5 << 20
+	maxReportBodySize  = maxReportPhotos*maxReportPhotoSize + 1<<20
+)
+
+var reportPhotoTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// CreateAssetReport lets an employee report an asset assigned to them as damaged, lost or stolen,
+// or schedule its return. It takes a multipart form with asset_id, type, description,
+// return_date (YYYY-MM-DD, returns only) and up to five "photos".
+func CreateAssetReport(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxReportBodySize)
+	if err := r.ParseMultipartForm(maxReportPhotoSize); err != nil {
+		http.Error(w, "invalid form, photos are limited to 5MB each", http.StatusBadRequest)
+		return
+	}
+
+	req := models.CreateAssetReportRequest{
+		AssetID:     r.FormValue("asset_id"),
+		Type:        r.FormValue("type"),
+		Description: strings.TrimSpace(r.FormValue("description")),
+	}
+
+	if req.AssetID == "" || req.Description == "" {
+		http.Error(w, "asset_id and description are required", http.StatusBadRequest)
+		return
+	}
+	if !utils.IsValidUUID(req.AssetID) {
+		http.Error(w, "asset_id must be an asset id", http.StatusBadRequest)
+		return
+	}
+	if !slices.Contains(assetReportTypes, req.Type) {
+		http.Error(w, "type must be one of damaged, lost, stolen or return", http.StatusBadRequest)
+		return
+	}
+
+	if v := r.FormValue("return_date"); v != "" {
+		if req.Type != "return" {
+			http.Error(w, "return_date is only allowed for returns", http.StatusBadRequest)
+			return
+		}
+		returnDate, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "return_date must be a date like 2024-01-31", http.StatusBadRequest)
+			return
+		}
+		req.ReturnDate = &returnDate
+	}
+
+	photos, err := readReportPhotos(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Photos = photos
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	authUserID := middleware.GetUserID(r)
+	current, err := db.GetActiveAssetStatus(tx, req.AssetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+		return
+	}
+	if current == nil || current.Status != "assigned" || current.AssignedToUser == nil || *current.AssignedToUser != authUserID {
+		http.Error(w, "asset is not assigned to you", http.StatusBadRequest)
+		return
+	}
+
+	hasOpen, err := db.HasOpenAssetReport(tx, req.AssetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to check open reports", http.StatusInternalServerError)
+		return
+	}
+	if hasOpen {
+		http.Error(w, "asset already has an open report", http.StatusConflict)
+		return
+	}
+
+	reportID, err := db.CreateAssetReport(tx, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to create report", http.StatusInternalServerError)
+		return
+	}
+
+	for i := range req.Photos {
+		if err = db.InsertAssetReportPhoto(tx, reportID, &req.Photos[i]); err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to store photos", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":   "Report created successfully",
+		"report_id": reportID,
+	})
+}
+
+// readReportPhotos reads the uploaded photos, checking their type from the content rather than the name
+func readReportPhotos(r *http.Request) ([]models.AssetReportPhotoUpload, error) {
+	headers := r.MultipartForm.File["photos"]
+	if len(headers) > maxReportPhotos {
+		return nil, errors.New("at most 5 photos can be attached")
+	}
+
+	var photos []models.AssetReportPhotoUpload
+	for _, header := range headers {
+		if header.Size > maxReportPhotoSize {
+			return nil, errors.New("photo " + header.Filename + " is larger than 5MB")
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return nil, errors.New("failed to read photo " + header.Filename)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, errors.New("failed to read photo " + header.Filename)
+		}
+
+		contentType := http.DetectContentType(data)
+		if !slices.Contains(reportPhotoTypes, contentType) {
+			return nil, errors.New("photo " + header.Filename + " must be a jpeg, png, gif or webp image")
+		}
+
+		photos = append(photos, models.AssetReportPhotoUpload{
+			FileName:    header.Filename,
+			ContentType: contentType,
+			Data:        data,
+		})
+	}
+	return photos, nil
+}
+
+// ListAssetReports is the queue for asset managers, employees only see their own reports
+func ListAssetReports(w http.ResponseWriter, r *http.Request) {
+	list, ok := parseListParams(w, r, db.ReportSortFields)
+	if !ok {
+		return
+	}
+
+	parseMulti := func(param string) []string {
+		values := strings.Split(r.URL.Query().Get(param), ",")
+		var cleaned []string
+		for _, v := range values {
+			if trimmed := strings.TrimSpace(v); trimmed != "" {
+				cleaned = append(cleaned, trimmed)
+			}
+		}
+		return cleaned
+	}
+
+	params := models.AssetReportFilterParams{
+		ReportedBy: r.URL.Query().Get("reported_by"),
+		Types:      parseMulti("type"),
+		Status:     parseMulti("status"),
+		ListParams: list,
+	}
+	if !isAssetManager(r) {
+		params.ReportedBy = middleware.GetUserID(r)
+	}
+	if params.ReportedBy != "" && !utils.IsValidUUID(params.ReportedBy) {
+		http.Error(w, "reported_by must be a user id", http.StatusBadRequest)
+		return
+	}
+	// one row past the page tells whether there is another
+	params.Limit++
+
+	reports, err := db.ListAssetReports(&params)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list reports", http.StatusInternalServerError)
+		return
+	}
+
+	total, err := db.CountAssetReports(&params)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to count reports", http.StatusInternalServerError)
+		return
+	}
+
+	writeListPage(w, r, list, reports, total, nil, func(report models.AssetReport) []string { return report.Cursor })
+}
+
+func GetAssetReport(w http.ResponseWriter, r *http.Request) {
+	reportID := chi.URLParam(r, "report_id")
+	if !utils.IsValidUUID(reportID) {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+
+	report, err := db.GetAssetReport(reportID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch report", http.StatusInternalServerError)
+		return
+	}
+	if report == nil || (!isAssetManager(r) && report.ReportedBy != middleware.GetUserID(r)) {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+func GetAssetReportPhoto(w http.ResponseWriter, r *http.Request) {
+	reportID := chi.URLParam(r, "report_id")
+	if !utils.IsValidUUID(reportID) {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+	photoID := chi.URLParam(r, "photo_id")
+	if !utils.IsValidUUID(photoID) {
+		http.Error(w, "photo not found", http.StatusNotFound)
+		return
+	}
+
+	report, err := db.GetAssetReport(reportID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch report", http.StatusInternalServerError)
+		return
+	}
+	if report == nil || (!isAssetManager(r) && report.ReportedBy != middleware.GetUserID(r)) {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+
+	photo, err := db.GetAssetReportPhoto(reportID, photoID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch photo", http.StatusInternalServerError)
+		return
+	}
+	if photo == nil {
+		http.Error(w, "photo not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", photo.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(photo.Data)))
+	w.Write(photo.Data)
+}
+
+// AcceptAssetReport acts on a report: returns are retrieved back to the available pool, damaged assets
+// move to the damaged state for repair, lost and stolen assets are disposed with the manager as approver.
+func AcceptAssetReport(w http.ResponseWriter, r *http.Request) {
+	reportID := chi.URLParam(r, "report_id")
+	if !utils.IsValidUUID(reportID) {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+
+	var body models.ResolveAssetReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	report, err := db.LockAssetReport(tx, reportID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch report", http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+	if report.Status != "open" {
+		http.Error(w, "report is already "+report.Status, http.StatusBadRequest)
+		return
+	}
+
+	// the asset must still be with the employee who reported it
+	current, err := db.GetActiveAssetStatus(tx, report.AssetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset status", http.StatusInternalServerError)
+		return
+	}
+	if current == nil || current.Status != "assigned" || current.AssignedToUser == nil || *current.AssignedToUser != report.ReportedBy {
+		http.Error(w, "asset is no longer assigned to the reporter", http.StatusBadRequest)
+		return
+	}
+
+	if err = db.ArchiveAssetStatus(tx, current.ID); err != nil {
+		http.Error(w, "failed to archive asset status", http.StatusInternalServerError)
+		return
+	}
+
+	remarks := report.Description
+	if body.Remarks != nil {
+		remarks = *body.Remarks
+	}
+
+	authUserID := middleware.GetUserID(r)
+	switch report.Type {
+	case "return":
+		err = db.InsertAssetStatusWithRemarks(tx, report.AssetID, "available", &remarks)
+	case "damaged":
+		err = db.InsertAssetStatusWithRemarks(tx, report.AssetID, "damaged", &remarks)
+	case "lost", "stolen":
+		_, err = recordDisposal(tx, report.AssetID, &models.DisposeAssetRequest{
+			Reason:     report.Type,
+			ApprovedBy: authUserID,
+			Remarks:    &remarks,
+		}, authUserID)
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update asset status", http.StatusInternalServerError)
+		return
+	}
+
+	if err = db.ResolveAssetReport(tx, reportID, "accepted", body.Remarks, authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to resolve report", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":   "Report accepted successfully",
+		"report_id": reportID,
+		"asset_id":  report.AssetID,
+	})
+}
+
+// RejectAssetReport closes a report without touching the asset, the remarks tell the employee why
+func RejectAssetReport(w http.ResponseWriter, r *http.Request) {
+	reportID := chi.URLParam(r, "report_id")
+	if !utils.IsValidUUID(reportID) {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+
+	var body models.ResolveAssetReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Remarks == nil || strings.TrimSpace(*body.Remarks) == "" {
+		http.Error(w, "remarks are required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	report, err := db.LockAssetReport(tx, reportID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch report", http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		http.Error(w, "report not found", http.StatusNotFound)
+		return
+	}
+	if report.Status != "open" {
+		http.Error(w, "report is already "+report.Status, http.StatusBadRequest)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	if err = db.ResolveAssetReport(tx, reportID, "rejected", body.Remarks, authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to resolve report", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":   "Report rejected successfully",
+		"report_id": reportID,
+	})
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strings"
+)
+
+// isAssetManager reports whether the caller works the request queue rather than filing requests
+func isAssetManager(r *http.Request) bool {
+	role := middleware.GetUserRole(r)
+	return role == "admin" || role == "asset_manager"
+}
+
+func CreateAssetRequest(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAssetRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Description == "" {
+		http.Error(w, "description is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.AssetType != nil {
+		if _, err := db.GetAssetType(*req.AssetType); err != nil {
+			if errors.Is(err, db.ErrUnknownAssetType) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Println(err.Error())
+			http.Error(w, "failed to resolve asset type", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	authUserID := middleware.GetUserID(r)
+	requestID, err := db.CreateAssetRequest(tx, &req, authUserID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to create request", http.StatusInternalServerError)
+		return
+	}
+
+	status := "pending"
+	if err = db.InsertAssetRequestEvent(tx, requestID, authUserID, &status, nil); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to record request history", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":    "Request created successfully",
+		"request_id": requestID,
+	})
+}
+
+// ListAssetRequests shows asset managers the whole queue and everyone else their own requests
+func ListAssetRequests(w http.ResponseWriter, r *http.Request) {
+	list, ok := parseListParams(w, r, db.RequestSortFields)
+	if !ok {
+		return
+	}
+
+	parseMulti := func(param string) []string {
+		values := strings.Split(r.URL.Query().Get(param), ",")
+		var cleaned []string
+		for _, v := range values {
+			if trimmed := strings.TrimSpace(v); trimmed != "" {
+				cleaned = append(cleaned, trimmed)
+			}
+		}
+		return cleaned
+	}
+
+	params := models.AssetRequestFilterParams{
+		RequestedBy: r.URL.Query().Get("requested_by"),
+		Status:      parseMulti("status"),
+		AssetTypes:  parseMulti("asset_type"),
+		ListParams:  list,
+	}
+	if !isAssetManager(r) {
+		params.RequestedBy = middleware.GetUserID(r)
+	}
+	if params.RequestedBy != "" && !utils.IsValidUUID(params.RequestedBy) {
+		http.Error(w, "requested_by must be a user id", http.StatusBadRequest)
+		return
+	}
+	// one row past the page tells whether there is another
+	params.Limit++
+
+	requests, err := db.ListAssetRequests(&params)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list requests", http.StatusInternalServerError)
+		return
+	}
+
+	total, err := db.CountAssetRequests(&params)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to count requests", http.StatusInternalServerError)
+		return
+	}
+
+	writeListPage(w, r, list, requests, total, nil, func(req models.AssetRequest) []string { return req.Cursor })
+}
+
+func GetAssetRequest(w http.ResponseWriter, r *http.Request) {
+	requestID := chi.URLParam(r, "request_id")
+	if !utils.IsValidUUID(requestID) {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+
+	req, err := db.GetAssetRequest(requestID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch request", http.StatusInternalServerError)
+		return
+	}
+
+	// other employees' requests are not found rather than forbidden
+	if req == nil || (!isAssetManager(r) && req.RequestedBy != middleware.GetUserID(r)) {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(req)
+}
+
+// CommentOnAssetRequest adds a comment to the history without changing the status
+func CommentOnAssetRequest(w http.ResponseWriter, r *http.Request) {
+	requestID := chi.URLParam(r, "request_id")
+	if !utils.IsValidUUID(requestID) {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+
+	var body models.AssetRequestActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Comment == nil || strings.TrimSpace(*body.Comment) == "" {
+		http.Error(w, "comment is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	authUserID := middleware.GetUserID(r)
+	req, err := db.LockAssetRequest(tx, requestID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch request", http.StatusInternalServerError)
+		return
+	}
+	if req == nil || (!isAssetManager(r) && req.RequestedBy != authUserID) {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+
+	if err = db.InsertAssetRequestEvent(tx, requestID, authUserID, nil, body.Comment); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to record request history", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte("comment added successfully"))
+}
+
+func ApproveAssetRequest(w http.ResponseWriter, r *http.Request) {
+	changeAssetRequestStatus(w, r, "approved", []string{"pending"}, false)
+}
+
+func RejectAssetRequest(w http.ResponseWriter, r *http.Request) {
+	changeAssetRequestStatus(w, r, "rejected", []string{"pending", "approved"}, true)
+}
+
+// CancelAssetRequest lets the requester withdraw a request that has not been fulfilled
+func CancelAssetRequest(w http.ResponseWriter, r *http.Request) {
+	changeAssetRequestStatus(w, r, "cancelled", []string{"pending", "approved"}, false)
+}
+
+// changeAssetRequestStatus moves a request from one of the allowed statuses to newStatus and records it
+// in the history. Cancelling is reserved for the requester, every other change for asset managers.
+func changeAssetRequestStatus(w http.ResponseWriter, r *http.Request, newStatus string, allowedFrom []string, commentRequired bool) {
+	requestID := chi.URLParam(r, "request_id")
+	if !utils.IsValidUUID(requestID) {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+
+	var body models.AssetRequestActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if commentRequired && (body.Comment == nil || strings.TrimSpace(*body.Comment) == "") {
+		http.Error(w, "comment is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	authUserID := middleware.GetUserID(r)
+	req, err := db.LockAssetRequest(tx, requestID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch request", http.StatusInternalServerError)
+		return
+	}
+	if req == nil {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+	if newStatus == "cancelled" && req.RequestedBy != authUserID {
+		http.Error(w, "only the requester can cancel a request", http.StatusForbidden)
+		return
+	}
+	if !slices.Contains(allowedFrom, req.Status) {
+		http.Error(w, "request in status '"+req.Status+"' cannot be moved to '"+newStatus+"'", http.StatusBadRequest)
+		return
+	}
+
+	if err = db.UpdateAssetRequestStatus(tx, requestID, newStatus, nil, authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update request", http.StatusInternalServerError)
+		return
+	}
+
+	if err = db.InsertAssetRequestEvent(tx, requestID, authUserID, &newStatus, body.Comment); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to record request history", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":    "Request updated successfully",
+		"request_id": requestID,
+		"status":     newStatus,
+	})
+}
+
+// FulfilAssetRequest assigns an available asset to the requester through the regular assign path
+func FulfilAssetRequest(w http.ResponseWriter, r *http.Request) {
+	requestID := chi.URLParam(r, "request_id")
+	if !utils.IsValidUUID(requestID) {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+
+	var body models.FulfilAssetRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.AssetID == "" {
+		http.Error(w, "asset_id is required", http.StatusBadRequest)
+		return
+	}
+	if !utils.IsValidUUID(body.AssetID) {
+		http.Error(w, "asset_id must be an asset id", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := db.GetAssetWithModel(body.AssetID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch asset", http.StatusInternalServerError)
+		return
+	}
+	if asset == nil {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	req, err := db.LockAssetRequest(tx, requestID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch request", http.StatusInternalServerError)
+		return
+	}
+	if req == nil {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+	if req.Status != "pending" && req.Status != "approved" {
+		http.Error(w, "request in status '"+req.Status+"' cannot be fulfilled", http.StatusBadRequest)
+		return
+	}
+	if req.AssetType != nil && *req.AssetType != asset.AssetType {
+		http.Error(w, "request is for a "+*req.AssetType+", asset is a "+asset.AssetType, http.StatusBadRequest)
+		return
+	}
+
+	// the requester may have been archived since filing the request
+	err = db.IsUserExistByID(req.RequestedBy, tx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "requester is no longer an active user", http.StatusBadRequest)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "error in finding user", http.StatusInternalServerError)
+		return
+	}
+
+	err = assignAssetToUser(tx, &models.AssignAssetRequest{AssetID: body.AssetID, UserID: req.RequestedBy})
+	if err != nil {
+		if errors.Is(err, errAssetNotAvailable) {
+			http.Error(w, "Asset is not available for assignment", http.StatusBadRequest)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to assign asset", http.StatusInternalServerError)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	if err = db.UpdateAssetRequestStatus(tx, requestID, "fulfilled", &body.AssetID, authUserID); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to update request", http.StatusInternalServerError)
+		return
+	}
+
+	status := "fulfilled"
+	if err = db.InsertAssetRequestEvent(tx, requestID, authUserID, &status, body.Comment); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to record request history", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":    "Request fulfilled successfully",
+		"request_id": requestID,
+		"asset_id":   body.AssetID,
+	})
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"database/sql"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/middleware"
+	"storex/models"
+	"storex/utils"
+	"strconv"
+	"strings"
+)
+
+func ListServices(w http.ResponseWriter, r *http.Request) {
+	// paging is optional, without it the first page of the default size is listed
+	limit, page := defaultListLimit, 1
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "limit is not a number", http.StatusBadRequest)
+			return
+		}
+		if n >= 1 && n <= maxListLimit {
+			limit = n
+		}
+	}
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "page is not a number", http.StatusBadRequest)
+			return
+		}
+		page = max(n, 1)
+	}
+
+	params := models.ServiceFilterParams{
+		Search: strings.TrimSpace(r.URL.Query().Get("search")),
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	}
+
+	services, err := db.ListServices(&params)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to list services", http.StatusInternalServerError)
+		return
+	}
+	if services == nil {
+		services = []models.ListServicesResponse{}
+	}
+
+	json.NewEncoder(w).Encode(services)
+}
+
+func CreateService(w http.ResponseWriter, r *http.Request) {
+	var service models.Service
+	if err := json.NewDecoder(r.Body).Decode(&service); err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+
+	service.Name = strings.TrimSpace(service.Name)
+	if service.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	if !utils.IsValidContactEmail(service.Email) {
+		http.Error(w, "invalid email", http.StatusBadRequest)
+		return
+	}
+
+	if !utils.IsValidPhone(service.Phone) {
+		http.Error(w, "invalid phone", http.StatusBadRequest)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	serviceID, err := db.CreateService(&service, authUserID)
+	if err != nil {
+		if strings.Contains(err.Error(), "unique") {
+			http.Error(w, "service with this email already exists", http.StatusConflict)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to create service", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":    "Service created successfully",
+		"service_id": serviceID,
+	})
+}
+
+func GetService(w http.ResponseWriter, r *http.Request) {
+	serviceID := chi.URLParam(r, "service_id")
+	if !utils.IsValidUUID(serviceID) {
+		http.Error(w, "service not found", http.StatusNotFound)
+		return
+	}
+
+	stats, err := db.GetServiceStats(serviceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "service not found", http.StatusNotFound)
+			return
+		}
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch service", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(stats)
+}
+
+func ServiceJobs(w http.ResponseWriter, r *http.Request) {
+	serviceID := chi.URLParam(r, "service_id")
+	if !utils.IsValidUUID(serviceID) {
+		http.Error(w, "service not found", http.StatusNotFound)
+		return
+	}
+
+	exists, err := db.IsServiceActive(serviceID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch service", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "service not found", http.StatusNotFound)
+		return
+	}
+
+	jobs, err := db.FetchServiceJobs(serviceID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch service jobs", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(jobs)
+}
+
+func UpdateService(w http.ResponseWriter, r *http.Request) {
+	serviceID := chi.URLParam(r, "service_id")
+	if serviceID == "" {
+		http.Error(w, "Missing service ID", http.StatusBadRequest)
+		return
+	}
+	if !utils.IsValidUUID(serviceID) {
+		http.Error(w, "service not found", http.StatusNotFound)
+		return
+	}
+
+	var req models.UpdateServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate provided fields
+	if req.Email != nil && !utils.IsValidContactEmail(*req.Email) {
+		http.Error(w, "Invalid email format", http.StatusBadRequest)
+		return
+	}
+	if req.Phone != nil && !utils.IsValidPhone(*req.Phone) {
+		http.Error(w, "Invalid phone", http.StatusBadRequest)
+		return
+	}
+
+	// Ensure at least one field is being updated
+	if req.Name == nil && req.Email == nil && req.Phone == nil && req.Location == nil {
+		http.Error(w, "No fields to update", http.StatusBadRequest)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	updated, err := db.UpdateService(authUserID, serviceID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "unique") {
+			http.Error(w, "Email already exists", http.StatusConflict)
+			return
+		}
+		log.Println("UpdateService error:", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if updated == 0 {
+		http.Error(w, "service not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Service updated successfully",
+	})
+}
+
+func DeleteService(w http.ResponseWriter, r *http.Request) {
+	serviceID := chi.URLParam(r, "service_id")
+	if !utils.IsValidUUID(serviceID) {
+		http.Error(w, "service not found", http.StatusNotFound)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		return
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	// Vendors still holding assets cannot be removed
+	activeCount, err := db.NumberOfAssetsInService(tx, serviceID)
+	if err != nil {
+		http.Error(w, "failed to check service assets", http.StatusInternalServerError)
+		return
+	}
+	if activeCount > 0 {
+		http.Error(w, "service cannot be deleted while it holds assets", http.StatusBadRequest)
+		return
+	}
+
+	authUserID := middleware.GetUserID(r)
+	archived, err := db.SoftDeleteService(tx, serviceID, authUserID)
+	if err != nil {
+		http.Error(w, "failed to archive service", http.StatusInternalServerError)
+		return
+	}
+	if archived == 0 {
+		http.Error(w, "service not found", http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte("service deleted successfully"))
+}
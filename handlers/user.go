@@ -8,30 +8,19 @@ import (
 	"storex/middleware"
 	"storex/models"
 	"storex/utils"
-	"strconv"
 	"strings"
 )
 
 func ListUsers(w http.ResponseWriter, r *http.Request) {
-
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-	if err != nil {
-		http.Error(w, "limit is not a number", http.StatusBadRequest)
+	list, ok := parseListParams(w, r, db.UserSortFields)
+	if !ok {
 		return
 	}
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil {
-		http.Error(w, "page is not a number", http.StatusBadRequest)
+	facets, ok := parseFacets(w, r, db.UserFacetFields)
+	if !ok {
 		return
 	}
 
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 10
-	}
-
 	parseMulti := func(param string) []string {
 		values := strings.Split(r.URL.Query().Get(param), ",")
 		var cleaned []string
@@ -49,9 +38,10 @@ func ListUsers(w http.ResponseWriter, r *http.Request) {
 		UserTypes:   parseMulti("user_type"),
 		AssetStatus: parseMulti("status"),
 		Roles:       parseMulti("role"),
-		Limit:       limit,
-		Offset:      (page - 1) * limit,
+		ListParams:  list,
 	}
+	// one row past the page tells whether there is another
+	params.Limit++
 
 	//later will add multiple filter of a type
 	users, err := db.ListUsers(&params)
@@ -61,12 +51,24 @@ func ListUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(users) == 0 {
-		http.Error(w, "no users found", http.StatusNotFound)
+	total, err := db.CountUsers(&params)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to count users", http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(users)
+	var facetCounts map[string][]models.FacetCount
+	if len(facets) > 0 {
+		facetCounts, err = db.UserFacets(&params, facets)
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "failed to count user facets", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeListPage(w, r, list, users, total, facetCounts, func(u models.ListUsersResponse) []string { return u.Cursor })
 }
 
 func CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -235,12 +237,13 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if assignedCount > 0 {
-		http.Error(w, "user cannot be deleted while assets are assigned", http.StatusBadRequest)
+		http.Error(w, "user cannot be deleted while assets are assigned, start an offboarding to collect them", http.StatusBadRequest)
 		return
 	}
 
 	// Soft delete user
-	err = db.SoftDeleteUser(tx, userID)
+	authUserID := middleware.GetUserID(r)
+	err = db.SoftDeleteUser(tx, userID, authUserID)
 	if err != nil {
 		http.Error(w, "failed to archive user", http.StatusInternalServerError)
 		return
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"storex/db"
+	"storex/models"
+	"time"
+)
+
+// WarrantyReport lists assets whose warranty expires between the from and to months (YYYY-MM),
+// grouped by month. It defaults to the next twelve months.
+func WarrantyReport(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 11, 0)
+
+	var err error
+	if v := r.URL.Query().Get("from"); v != "" {
+		from, err = time.Parse("2006-01", v)
+		if err != nil {
+			http.Error(w, "from must be a month like 2024-01", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		to, err = time.Parse("2006-01", v)
+		if err != nil {
+			http.Error(w, "to must be a month like 2024-12", http.StatusBadRequest)
+			return
+		}
+	}
+	if to.Before(from) {
+		http.Error(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+
+	// to is inclusive, so stop at the start of the following month
+	assets, err := db.ListWarrantyExpiring(from, to.AddDate(0, 1, 0))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "failed to fetch warranty report", http.StatusInternalServerError)
+		return
+	}
+
+	report := []models.WarrantyMonth{}
+	for _, asset := range assets {
+		month := asset.WarrantyExpDate.UTC().Format("2006-01")
+		if len(report) == 0 || report[len(report)-1].Month != month {
+			report = append(report, models.WarrantyMonth{Month: month})
+		}
+		current := &report[len(report)-1]
+		current.Count++
+		current.Assets = append(current.Assets, asset)
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,435 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"storex/db"
+	"storex/models"
+	"storex/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrImportNotFound = errors.New("import not found")
+
+// specColumnPrefix marks type specific spec columns, e.g. spec_ram_gb
+const specColumnPrefix = "spec_"
+
+var dateLayouts = []string{"2006-01-02", time.RFC3339, "2006/01/02"}
+
+type Options struct {
+	FileName  string
+	CreatedBy string
+	DryRun    bool
+	BatchSize int    // 0 imports every row in a single transaction
+	ImportID  string // resumes an earlier batched import of the same file
+}
+
+type item struct {
+	line int
+	req  models.CreateAssetRequest
+}
+
+// Run validates every row of the file and, unless it is a dry run, inserts the assets.
+// Nothing is written when any row is invalid.
+func Run(data []byte, opts Options) (*models.ImportReport, error) {
+	rows, err := ReadRows(opts.FileName, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: file has no data rows", ErrInvalidFile)
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	report := &models.ImportReport{
+		DryRun:    opts.DryRun,
+		TotalRows: len(rows),
+		RowErrors: []models.ImportRowError{},
+	}
+
+	if opts.ImportID != "" {
+		imp, err := db.GetAssetImport(opts.ImportID)
+		if err != nil {
+			return nil, err
+		}
+		if imp == nil {
+			return nil, ErrImportNotFound
+		}
+		if imp.Checksum != checksum {
+			return nil, fmt.Errorf("%w: file does not match import %s", ErrInvalidFile, imp.ID)
+		}
+
+		report.ImportID = &imp.ID
+		report.SkippedRows = imp.ProcessedRows
+		if imp.Status == "completed" {
+			report.Status = "completed"
+			return report, nil
+		}
+		rows = rows[imp.ProcessedRows:]
+	}
+
+	items, rowErrors, err := validateRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	report.ValidRows = len(items)
+	report.InvalidRows = len(rowErrors)
+	report.RowErrors = append(report.RowErrors, rowErrors...)
+
+	if len(rowErrors) > 0 {
+		report.Status = "invalid"
+		return report, nil
+	}
+	if opts.DryRun {
+		report.Status = "validated"
+		return report, nil
+	}
+
+	// without batches everything commits at once, a resumed import still records its progress
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		if report.ImportID == nil {
+			if err := commitBatch(items, opts.CreatedBy, "", 0, 0); err != nil {
+				report.Status = "failed"
+				report.Error = err.Error()
+				return report, nil
+			}
+			report.ImportedRows = len(items)
+			report.Status = "completed"
+			return report, nil
+		}
+		batchSize = len(items)
+	}
+
+	if report.ImportID == nil {
+		importID, err := db.CreateAssetImport(&models.AssetImport{
+			FileName:  opts.FileName,
+			Checksum:  checksum,
+			TotalRows: report.TotalRows,
+			CreatedBy: opts.CreatedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		report.ImportID = &importID
+	}
+
+	processed := report.SkippedRows
+	for start := 0; start < len(items); start += batchSize {
+		end := min(start+batchSize, len(items))
+
+		if err := commitBatch(items[start:end], opts.CreatedBy, *report.ImportID, processed+end-start, report.TotalRows); err != nil {
+			if ferr := db.FailAssetImport(*report.ImportID, err.Error()); ferr != nil {
+				return nil, ferr
+			}
+			report.Status = "failed"
+			report.Error = err.Error()
+			return report, nil
+		}
+		processed += end - start
+		report.ImportedRows += end - start
+	}
+
+	report.Status = "completed"
+	return report, nil
+}
+
+// commitBatch inserts the items in one transaction, recording progress when it belongs to a batched import
+func commitBatch(items []item, createdBy string, importID string, processed int, total int) (err error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer db.TxFinalizer(tx, &err)
+
+	for _, it := range items {
+		if _, err = db.InsertAssetFromRequest(tx, &it.req, createdBy); err != nil {
+			return fmt.Errorf("row %d: %w", it.line, err)
+		}
+	}
+
+	if importID != "" {
+		status := "in_progress"
+		if processed >= total {
+			status = "completed"
+		}
+		err = db.UpdateAssetImportProgress(tx, importID, processed, status)
+	}
+	return err
+}
+
+func validateRows(rows []Row) ([]item, []models.ImportRowError, error) {
+	var serials []string
+	for _, row := range rows {
+		if serial := row.Values["serial_no"]; serial != "" {
+			serials = append(serials, serial)
+		}
+	}
+	existing, err := db.ExistingSerialNos(serials)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	types := map[string]*models.AssetTypeDefinition{}
+	seen := map[string]int{}
+	strict := utils.CatalogStrictMode()
+	catalog := map[string]catalogModel{}
+	var locations map[string]string
+
+	var items []item
+	var rowErrors []models.ImportRowError
+	for _, row := range rows {
+		req, errs, err := parseRow(row, types)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if req.SerialNo != "" {
+			if line, ok := seen[req.SerialNo]; ok {
+				errs = append(errs, fmt.Sprintf("serial_no duplicates row %d", line))
+			} else {
+				seen[req.SerialNo] = row.Line
+			}
+			if existing[req.SerialNo] {
+				errs = append(errs, "serial_no already exists")
+			}
+		}
+
+		// the location column takes a location id or its path, e.g. "Pune HQ / Floor 2 / Room 204"
+		if loc := strings.TrimSpace(row.Values["location"]); loc != "" {
+			if locations == nil {
+				if locations, err = loadLocations(); err != nil {
+					return nil, nil, err
+				}
+			}
+			if id, ok := locations[strings.ToLower(loc)]; ok {
+				req.LocationID = &id
+			} else {
+				errs = append(errs, "unknown location "+loc)
+			}
+		}
+
+		if len(errs) == 0 {
+			model, err := lookupModel(catalog, &req.Model)
+			if err != nil {
+				return nil, nil, err
+			}
+			// in strict mode only catalog brands and models are accepted
+			if strict && model.unknown != nil {
+				errs = append(errs, model.unknown.Error())
+			} else if def := types[req.Model.AssetType]; def != nil {
+				specs, _ := req.Specs.(map[string]interface{})
+				if _, err := utils.ValidateSpecs(def.Fields, utils.MergeSpecs(model.defaults, specs), false); err != nil {
+					errs = append(errs, err.Error())
+				}
+			}
+		}
+
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: row.Line, SerialNo: req.SerialNo, Errors: errs})
+			continue
+		}
+		items = append(items, item{line: row.Line, req: req})
+	}
+	return items, rowErrors, nil
+}
+
+// loadLocations maps the ids and lower-cased paths of the active locations to their id
+func loadLocations() (map[string]string, error) {
+	list, err := db.ListLocations(nil)
+	if err != nil {
+		return nil, err
+	}
+	locations := map[string]string{}
+	for _, loc := range list {
+		locations[strings.ToLower(loc.ID)] = loc.ID
+		locations[strings.ToLower(loc.Path)] = loc.ID
+	}
+	return locations, nil
+}
+
+// catalogModel is what validateRows needs of the model of a row
+type catalogModel struct {
+	defaults map[string]interface{}
+	unknown  error // the model is not in the catalog yet
+}
+
+// lookupModel finds the catalog model of a row once per brand and model
+func lookupModel(catalog map[string]catalogModel, req *models.CreateModelRequest) (catalogModel, error) {
+	key := strings.ToLower(req.Brand.Name + "\x00" + req.Name + "\x00" + req.AssetType)
+	if model, ok := catalog[key]; ok {
+		return model, nil
+	}
+
+	var model catalogModel
+	modelID, err := db.FindModelID(req)
+	switch {
+	case errors.Is(err, db.ErrUnknownBrand) || errors.Is(err, db.ErrUnknownModel):
+		model.unknown = err
+	case err != nil:
+		return model, err
+	default:
+		if model.defaults, err = db.GetModelDefaultSpecs(db.DB, modelID); err != nil {
+			return model, err
+		}
+	}
+	catalog[key] = model
+	return model, nil
+}
+
+// parseRow turns a row into a create request, validation problems are returned as messages
+func parseRow(row Row, types map[string]*models.AssetTypeDefinition) (models.CreateAssetRequest, []string, error) {
+	v := row.Values
+	req := models.CreateAssetRequest{
+		Model: models.CreateModelRequest{
+			Name:      v["model"],
+			AssetType: v["asset_type"],
+			Brand:     models.CreateBrandRequest{Name: v["brand"]},
+		},
+		SerialNo: v["serial_no"],
+		OwnedBy:  v["owned_by"],
+	}
+
+	var errs []string
+	for _, col := range []string{"brand", "model", "asset_type", "serial_no", "owned_by", "purchased_date"} {
+		if v[col] == "" {
+			errs = append(errs, col+" is required")
+		}
+	}
+
+	if req.OwnedBy != "" && req.OwnedBy != "remote_state" && req.OwnedBy != "client" {
+		errs = append(errs, "owned_by must be 'remote_state' or 'client'")
+	}
+
+	amounts := []struct {
+		col string
+		dst **float64
+	}{
+		{"purchase_cost", &req.PurchaseCost},
+		{"salvage_value", &req.SalvageValue},
+	}
+	for _, a := range amounts {
+		if v[a.col] == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(v[a.col], 64)
+		if err != nil {
+			errs = append(errs, a.col+" must be a number")
+			continue
+		}
+		*a.dst = &n
+	}
+	if currency := strings.ToUpper(v["currency"]); currency != "" {
+		req.Currency = &currency
+	}
+	if err := utils.ValidateAssetCost(req.PurchaseCost, req.SalvageValue, req.Currency); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	dates := []struct {
+		col string
+		dst **time.Time
+	}{
+		{"purchased_date", &req.PurchasedDate},
+		{"warranty_start_date", &req.WarrantyStartDate},
+		{"warranty_exp_date", &req.WarrantyExpDate},
+	}
+	for _, d := range dates {
+		if v[d.col] == "" {
+			continue
+		}
+		t, ok := parseDate(v[d.col])
+		if !ok {
+			errs = append(errs, d.col+" must be a date like 2024-01-31")
+			continue
+		}
+		*d.dst = &t
+	}
+
+	if req.Model.AssetType == "" {
+		return req, errs, nil
+	}
+
+	def, ok := types[req.Model.AssetType]
+	if !ok {
+		var err error
+		def, err = db.GetAssetType(req.Model.AssetType)
+		if err != nil && !errors.Is(err, db.ErrUnknownAssetType) {
+			return req, nil, err
+		}
+		types[req.Model.AssetType] = def
+	}
+	if def == nil {
+		errs = append(errs, "unsupported asset_type "+req.Model.AssetType)
+		return req, errs, nil
+	}
+
+	specs, specErrs := parseSpecs(v, def.Fields)
+	errs = append(errs, specErrs...)
+	// required fields may come from the model defaults, validateRows checks them
+	if len(specErrs) == 0 {
+		if _, err := utils.ValidateSpecs(def.Fields, specs, true); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	req.Specs = specs
+
+	return req, errs, nil
+}
+
+// parseSpecs converts spec_* columns to the JSON types of the matching spec fields
+func parseSpecs(values map[string]string, fields []models.SpecField) (map[string]interface{}, []string) {
+	specs := map[string]interface{}{}
+	var errs []string
+
+	for _, col := range slices.Sorted(maps.Keys(values)) {
+		raw := values[col]
+		name, ok := strings.CutPrefix(col, specColumnPrefix)
+		if !ok {
+			continue
+		}
+
+		idx := slices.IndexFunc(fields, func(f models.SpecField) bool { return f.Name == name })
+		if idx < 0 {
+			errs = append(errs, "unknown spec column "+col)
+			continue
+		}
+
+		switch fields[idx].Type {
+		case "int", "number":
+			n, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				errs = append(errs, col+" must be a number")
+				continue
+			}
+			specs[name] = n
+		case "bool":
+			b, err := strconv.ParseBool(strings.ToLower(raw))
+			if err != nil {
+				errs = append(errs, col+" must be true or false")
+				continue
+			}
+			specs[name] = b
+		default:
+			specs[name] = raw
+		}
+	}
+	return specs, errs
+}
+
+func parseDate(s string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
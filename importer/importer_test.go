@@ -0,0 +1,142 @@
+package importer
+
+import (
+	"math"
+	"reflect"
+	"storex/models"
+	"testing"
+	"time"
+)
+
+var testTypes = map[string]*models.AssetTypeDefinition{
+	"laptop": {Name: "laptop", Fields: []models.SpecField{
+		{Name: "ram_gb", Type: "int", Required: true},
+		{Name: "screen_size_inch", Type: "number"},
+		{Name: "touchscreen", Type: "bool"},
+		{Name: "storage_type", Type: "string", Enum: []string{"SSD", "HDD"}},
+	}},
+	// an asset type that does not exist, looked up once and remembered
+	"toaster": nil,
+}
+
+func laptopRow(values map[string]string) Row {
+	row := Row{Line: 2, Values: map[string]string{
+		"brand": "Dell", "model": "Latitude 5440", "asset_type": "laptop",
+		"serial_no": "SN-1", "owned_by": "remote_state", "purchased_date": "2024-01-15",
+	}}
+	for k, v := range values {
+		if v == "" {
+			delete(row.Values, k)
+		} else {
+			row.Values[k] = v
+		}
+	}
+	return row
+}
+
+func TestParseRow(t *testing.T) {
+	row := laptopRow(map[string]string{
+		"purchase_cost": "1250.50", "salvage_value": "100", "currency": "inr",
+		"warranty_exp_date": "2027/01/14", "spec_ram_gb": "16", "spec_touchscreen": "FALSE",
+	})
+
+	req, errs, err := parseRow(row, testTypes)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("got %v, %v", errs, err)
+	}
+
+	if req.Model.Brand.Name != "Dell" || req.Model.Name != "Latitude 5440" || req.SerialNo != "SN-1" {
+		t.Errorf("got %+v", req)
+	}
+	if *req.PurchaseCost != 1250.5 || *req.SalvageValue != 100 || *req.Currency != "INR" {
+		t.Errorf("got cost %v, salvage %v, currency %v", *req.PurchaseCost, *req.SalvageValue, *req.Currency)
+	}
+	if !req.PurchasedDate.Equal(time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)) ||
+		!req.WarrantyExpDate.Equal(time.Date(2027, time.January, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got purchased %v and warranty end %v", req.PurchasedDate, req.WarrantyExpDate)
+	}
+	if want := map[string]interface{}{"ram_gb": 16.0, "touchscreen": false}; !reflect.DeepEqual(req.Specs, want) {
+		t.Errorf("got specs %v, want %v", req.Specs, want)
+	}
+}
+
+func TestParseRowProblems(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   []string
+	}{
+		{"missing columns", map[string]string{"brand": "", "serial_no": ""},
+			[]string{"brand is required", "serial_no is required"}},
+		{"bad owner", map[string]string{"owned_by": "vendor"},
+			[]string{"owned_by must be 'remote_state' or 'client'"}},
+		{"cost not a number", map[string]string{"purchase_cost": "12,50"},
+			[]string{"purchase_cost must be a number"}},
+		{"NaN cost", map[string]string{"purchase_cost": "NaN"},
+			[]string{"invalid depreciation: purchase_cost and salvage_value must be finite numbers"}},
+		{"salvage above cost", map[string]string{"purchase_cost": "100", "salvage_value": "150"},
+			[]string{"invalid depreciation: salvage_value cannot exceed purchase_cost"}},
+		{"bad date", map[string]string{"purchased_date": "15/01/2024"},
+			[]string{"purchased_date must be a date like 2024-01-31"}},
+		{"unknown asset type", map[string]string{"asset_type": "toaster"},
+			[]string{"unsupported asset_type toaster"}},
+		{"spec not a number", map[string]string{"spec_ram_gb": "sixteen"},
+			[]string{"spec_ram_gb must be a number"}},
+		{"spec infinite", map[string]string{"spec_screen_size_inch": "+Inf"},
+			[]string{"invalid specs: screen_size_inch must be a number"}},
+		{"spec not whole", map[string]string{"spec_ram_gb": "15.5"},
+			[]string{"invalid specs: ram_gb must be a whole number"}},
+		{"spec outside enum", map[string]string{"spec_storage_type": "tape"},
+			[]string{"invalid specs: storage_type must be one of [SSD HDD]"}},
+		{"spec not a bool", map[string]string{"spec_touchscreen": "yes"},
+			[]string{"spec_touchscreen must be true or false"}},
+		{"unknown spec", map[string]string{"spec_gpu": "none", "spec_ram_gb": "x"},
+			[]string{"unknown spec column spec_gpu", "spec_ram_gb must be a number"}},
+	}
+	for _, tt := range tests {
+		_, errs, err := parseRow(laptopRow(tt.values), testTypes)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(errs, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, errs, tt.want)
+		}
+	}
+}
+
+func TestParseSpecs(t *testing.T) {
+	fields := testTypes["laptop"].Fields
+	specs, errs := parseSpecs(map[string]string{
+		"serial_no":             "SN-1",
+		"spec_ram_gb":           "32",
+		"spec_screen_size_inch": "14.0",
+		"spec_touchscreen":      "T",
+		"spec_storage_type":     "SSD",
+	}, fields)
+
+	want := map[string]interface{}{"ram_gb": 32.0, "screen_size_inch": 14.0, "touchscreen": true, "storage_type": "SSD"}
+	if len(errs) > 0 || !reflect.DeepEqual(specs, want) {
+		t.Errorf("got %v, %v, want %v", specs, errs, want)
+	}
+
+	// NaN parses as a float, ValidateSpecs has to catch it
+	specs, errs = parseSpecs(map[string]string{"spec_screen_size_inch": "NaN"}, fields)
+	if n, _ := specs["screen_size_inch"].(float64); len(errs) > 0 || !math.IsNaN(n) {
+		t.Errorf("got %v, %v", specs, errs)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	for _, s := range []string{"2024-01-31", "2024/01/31", "2024-01-31T00:00:00Z"} {
+		if got, ok := parseDate(s); !ok || !got.Equal(want) {
+			t.Errorf("%q: got %v, %v", s, got, ok)
+		}
+	}
+	for _, s := range []string{"31/01/2024", "2024-02-30", "Jan 31 2024"} {
+		if _, ok := parseDate(s); ok {
+			t.Errorf("%q: accepted", s)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidFile = errors.New("invalid import file")
+
+// Row is one data line of an import file keyed by normalised header name
+type Row struct {
+	Line   int // line in the file, the header is line 1
+	Values map[string]string
+}
+
+// ReadRows parses a CSV or XLSX file, the format is picked from the file extension
+func ReadRows(fileName string, data []byte) ([]Row, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return readCSV(bytes.NewReader(data))
+	case ".xlsx":
+		return readXLSX(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("%w: only .csv and .xlsx files are supported", ErrInvalidFile)
+	}
+}
+
+func readCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	return toRows(records)
+}
+
+func readXLSX(r io.Reader) ([]Row, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("%w: workbook has no sheets", ErrInvalidFile)
+	}
+
+	// only the first sheet is imported
+	records, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	return toRows(records)
+}
+
+func toRows(records [][]string) ([]Row, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidFile)
+	}
+
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = normaliseHeader(h)
+	}
+
+	var rows []Row
+	for i, record := range records[1:] {
+		values := map[string]string{}
+		empty := true
+		for j, v := range record {
+			if j >= len(header) || header[j] == "" {
+				continue
+			}
+			v = strings.TrimSpace(v)
+			if v != "" {
+				values[header[j]] = v
+				empty = false
+			}
+		}
+
+		// blank lines are common at the end of spreadsheets
+		if empty {
+			continue
+		}
+		rows = append(rows, Row{Line: i + 2, Values: values})
+	}
+	return rows, nil
+}
+
+func normaliseHeader(h string) string {
+	h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	return strings.Join(strings.Fields(h), "_")
+}
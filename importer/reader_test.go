@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"errors"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"testing"
+)
+
+func TestReadRowsCSV(t *testing.T) {
+	data := "\ufeffSerial No , Asset Type,spec_ram_gb,\n" +
+		"SN-1,  laptop ,16,ignored\n" +
+		",,,\n" +
+		"SN-2,monitor\n" +
+		"SN-3,laptop,8,x,extra\n"
+
+	rows, err := ReadRows("assets.CSV", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Row{
+		{Line: 2, Values: map[string]string{"serial_no": "SN-1", "asset_type": "laptop", "spec_ram_gb": "16"}},
+		{Line: 4, Values: map[string]string{"serial_no": "SN-2", "asset_type": "monitor"}},
+		{Line: 5, Values: map[string]string{"serial_no": "SN-3", "asset_type": "laptop", "spec_ram_gb": "8"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %+v, want %+v", rows, want)
+	}
+}
+
+func TestReadRowsXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	for cell, v := range map[string]any{
+		"A1": "Serial No", "B1": "Purchased Date", "C1": "Purchase Cost",
+		"A2": "SN-1", "B2": "2024-01-15", "C2": 1250.5,
+		"A4": " SN-2 ",
+	} {
+		if err := f.SetCellValue("Sheet1", cell, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ReadRows("assets.xlsx", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Row{
+		{Line: 2, Values: map[string]string{"serial_no": "SN-1", "purchased_date": "2024-01-15", "purchase_cost": "1250.5"}},
+		{Line: 4, Values: map[string]string{"serial_no": "SN-2"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %+v, want %+v", rows, want)
+	}
+}
+
+func TestReadRowsRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+	}{
+		{"unsupported extension", "assets.xls", "serial_no\nSN-1\n"},
+		{"no extension", "assets", "serial_no\nSN-1\n"},
+		{"empty csv", "assets.csv", ""},
+		{"broken quotes", "assets.csv", "serial_no\n\"SN-1\n"},
+		{"not a workbook", "assets.xlsx", "serial_no\nSN-1\n"},
+	}
+	for _, tt := range tests {
+		if _, err := ReadRows(tt.fileName, []byte(tt.data)); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("%s: got %v, want ErrInvalidFile", tt.name, err)
+		}
+	}
+}
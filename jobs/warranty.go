@@ -0,0 +1,165 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"storex/db"
+	"storex/models"
+	"storex/notifier"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type WarrantyReminderConfig struct {
+	Windows  []int // days before expiry a reminder goes out, e.g. 90, 30, 7
+	Interval time.Duration
+}
+
+// WarrantyReminderConfigFromEnv reads WARRANTY_REMINDER_DAYS ("90,30,7") and WARRANTY_CHECK_INTERVAL ("24h")
+func WarrantyReminderConfigFromEnv() WarrantyReminderConfig {
+	cfg := WarrantyReminderConfig{
+		Windows:  []int{90, 30, 7},
+		Interval: 24 * time.Hour,
+	}
+
+	if v := os.Getenv("WARRANTY_REMINDER_DAYS"); v != "" {
+		var windows []int
+		for _, part := range strings.Split(v, ",") {
+			days, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || days <= 0 {
+				log.Printf("ignoring invalid WARRANTY_REMINDER_DAYS entry %q", part)
+				continue
+			}
+			windows = append(windows, days)
+		}
+		if len(windows) > 0 {
+			cfg.Windows = windows
+		}
+	}
+
+	if v := os.Getenv("WARRANTY_CHECK_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			log.Printf("ignoring invalid WARRANTY_CHECK_INTERVAL %q", v)
+		} else {
+			cfg.Interval = interval
+		}
+	}
+
+	slices.Sort(cfg.Windows)
+	cfg.Windows = slices.Compact(cfg.Windows)
+	return cfg
+}
+
+// StartWarrantyReminders checks warranties right away and then on every interval until ctx is done
+func StartWarrantyReminders(ctx context.Context, n notifier.Notifier, cfg WarrantyReminderConfig) {
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := RunWarrantyReminders(ctx, n, cfg, time.Now()); err != nil {
+				log.Printf("warranty reminders: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunWarrantyReminders sends one reminder per asset for the smallest window its warranty falls in.
+// Reminders are claimed in the database before sending so they survive restarts.
+func RunWarrantyReminders(ctx context.Context, n notifier.Notifier, cfg WarrantyReminderConfig, now time.Time) error {
+	if len(cfg.Windows) == 0 {
+		return nil
+	}
+
+	maxWindow := cfg.Windows[len(cfg.Windows)-1]
+	assets, err := db.ListWarrantyExpiring(now, now.AddDate(0, 0, maxWindow))
+	if err != nil {
+		return fmt.Errorf("failed to list expiring warranties: %w", err)
+	}
+
+	// a failed claim skips only that asset, the ones already claimed still have to be sent or released
+	var errs []error
+	claimed := map[int][]models.WarrantyAsset{}
+	for _, asset := range assets {
+		daysLeft := int(math.Ceil(asset.WarrantyExpDate.Sub(now).Hours() / 24))
+		idx := slices.IndexFunc(cfg.Windows, func(w int) bool { return daysLeft <= w })
+		if idx < 0 {
+			continue
+		}
+		window := cfg.Windows[idx]
+
+		ok, err := db.ClaimWarrantyReminder(asset.AssetID, window, asset.WarrantyExpDate)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to claim reminder for asset %s: %w", asset.AssetID, err))
+			continue
+		}
+		if ok {
+			claimed[window] = append(claimed[window], asset)
+		}
+	}
+
+	if len(claimed) == 0 {
+		return errors.Join(errs...)
+	}
+
+	recipients, err := db.ListUserEmailsByRoles([]string{"admin", "asset_manager"})
+	if err == nil && len(recipients) == 0 {
+		err = errors.New("no admin or asset manager to notify")
+	}
+
+	for _, window := range cfg.Windows {
+		batch := claimed[window]
+		if len(batch) == 0 {
+			continue
+		}
+
+		sendErr := err
+		if sendErr == nil {
+			sendErr = n.Notify(ctx, warrantyMessage(recipients, window, batch))
+		}
+		if sendErr == nil {
+			continue
+		}
+
+		// put the claims back so the next run tries again
+		errs = append(errs, fmt.Errorf("%d day reminder: %w", window, sendErr))
+		for _, asset := range batch {
+			if rerr := db.ReleaseWarrantyReminder(asset.AssetID, window, asset.WarrantyExpDate); rerr != nil {
+				errs = append(errs, rerr)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func warrantyMessage(recipients []string, window int, assets []models.WarrantyAsset) notifier.Message {
+	var body strings.Builder
+	fmt.Fprintf(&body, "The warranty of the following %d asset(s) expires within %d days:\n\n", len(assets), window)
+	for _, a := range assets {
+		holder := "unassigned"
+		if a.AssignedUserName != nil {
+			holder = "with " + *a.AssignedUserName
+		}
+		fmt.Fprintf(&body, "- %s %s (%s, serial %s) expires %s, %s\n",
+			a.BrandName, a.ModelName, a.AssetType, a.SerialNo, a.WarrantyExpDate.Format("2006-01-02"), holder)
+	}
+
+	return notifier.Message{
+		To:      recipients,
+		Subject: fmt.Sprintf("Storex: %d warranties expiring within %d days", len(assets), window),
+		Body:    body.String(),
+	}
+}
@@ -0,0 +1,229 @@
+package labels
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"storex/models"
+)
+
+var ErrInvalidOptions = errors.New("invalid label options")
+
+type Options struct {
+	Symbology string // "qr" or "code128"
+	Encode    string // "id" or "serial", what the code carries
+}
+
+// Validate fills in the defaults, QR codes carrying the asset id
+func (o *Options) Validate() error {
+	if o.Symbology == "" {
+		o.Symbology = "qr"
+	}
+	if o.Encode == "" {
+		o.Encode = "id"
+	}
+	if o.Symbology != "qr" && o.Symbology != "code128" {
+		return fmt.Errorf("%w: code must be qr or code128", ErrInvalidOptions)
+	}
+	if o.Encode != "id" && o.Encode != "serial" {
+		return fmt.Errorf("%w: encode must be id or serial", ErrInvalidOptions)
+	}
+	return nil
+}
+
+func (o *Options) content(asset *models.ListAssetsResponse) string {
+	if o.Encode == "serial" {
+		return asset.SerialNo
+	}
+	return asset.ID
+}
+
+// Code returns the barcode of the asset scaled to width x height pixels
+func Code(asset *models.ListAssetsResponse, opts Options, width int, height int) (image.Image, error) {
+	var code barcode.Barcode
+	var err error
+	if opts.Symbology == "qr" {
+		code, err = qr.Encode(opts.content(asset), qr.M, qr.Auto)
+	} else {
+		code, err = code128.Encode(opts.content(asset))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode label of asset %s: %w", asset.ID, err)
+	}
+	return barcode.Scale(code, width, height)
+}
+
+func lines(asset *models.ListAssetsResponse) []string {
+	return []string{
+		asset.BrandName + " " + asset.ModelName,
+		"S/N " + asset.SerialNo,
+		asset.AssetType + " " + asset.ID[:min(8, len(asset.ID))],
+	}
+}
+
+// Sheet layout: A4 with 3 x 7 labels of 63.5 x 38.1 mm, the common 21 per sheet stock
+const (
+	sheetColumns = 3
+	sheetRows    = 7
+	labelWidth   = 63.5
+	labelHeight  = 38.1
+	marginLeft   = 7.2
+	marginTop    = 15.1
+	columnGap    = 2.5
+	labelPadding = 3.0
+)
+
+// RenderPDF writes A4 label sheets, one label per asset
+func RenderPDF(w io.Writer, assets []models.ListAssetsResponse, opts Options) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for i := range assets {
+		asset := &assets[i]
+		slot := i % (sheetColumns * sheetRows)
+		if slot == 0 {
+			pdf.AddPage()
+		}
+		x := marginLeft + float64(slot%sheetColumns)*(labelWidth+columnGap)
+		y := marginTop + float64(slot/sheetColumns)*labelHeight
+
+		// codes are drawn as PNG at roughly 300 dpi
+		codeW, codeH := 32.0, 32.0
+		if opts.Symbology == "code128" {
+			codeW, codeH = labelWidth-2*labelPadding, 14.0
+		}
+		img, err := Code(asset, opts, int(codeW*12), int(codeH*12))
+		if err != nil {
+			return err
+		}
+		// barcodes come out as 16-bit grey which gofpdf cannot embed
+		gray := image.NewGray(img.Bounds())
+		draw.Draw(gray, gray.Bounds(), img, img.Bounds().Min, draw.Src)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, gray); err != nil {
+			return err
+		}
+		name := fmt.Sprintf("code-%d", i)
+		imgOpts := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(name, imgOpts, &buf)
+		pdf.ImageOptions(name, x+labelPadding, y+labelPadding, codeW, codeH, false, imgOpts, 0, "")
+
+		// text sits right of a QR code and under a barcode
+		textX, textY, textW := x+labelPadding+codeW+2, y+labelPadding+2, labelWidth-codeW-2*labelPadding-2
+		if opts.Symbology == "code128" {
+			textX, textY, textW = x+labelPadding, y+labelPadding+codeH+2, codeW
+		}
+		for j, line := range lines(asset) {
+			style := ""
+			if j == 0 {
+				style = "B"
+			}
+			pdf.SetFont("Helvetica", style, 7)
+			pdf.SetXY(textX, textY+float64(j)*4)
+			pdf.CellFormat(textW, 4, fit(pdf, tr(line), textW), "", 0, "L", false, 0, "")
+		}
+	}
+
+	if len(assets) == 0 {
+		pdf.AddPage()
+	}
+	return pdf.Output(w)
+}
+
+// fit cuts text that would run off the label
+func fit(pdf *gofpdf.Fpdf, s string, width float64) string {
+	for len(s) > 0 && pdf.GetStringWidth(s) > width {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// PNG layout: three labels per row at 10 px per mm
+const (
+	pngScale      = 10
+	pngTextScale  = 2
+	pngLabelW     = int(labelWidth * pngScale)
+	pngLabelH     = int(labelHeight * pngScale)
+	pngPadding    = int(labelPadding * pngScale)
+	pngLineHeight = 15 * pngTextScale
+)
+
+// RenderPNG writes the labels as one image, three per row, for label printers that take images
+func RenderPNG(w io.Writer, assets []models.ListAssetsResponse, opts Options) error {
+	columns := min(len(assets), sheetColumns)
+	rows := (len(assets) + sheetColumns - 1) / sheetColumns
+	sheet := image.NewRGBA(image.Rect(0, 0, max(columns, 1)*pngLabelW, max(rows, 1)*pngLabelH))
+	draw.Draw(sheet, sheet.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for i := range assets {
+		asset := &assets[i]
+		x := (i % sheetColumns) * pngLabelW
+		y := (i / sheetColumns) * pngLabelH
+
+		codeW, codeH := 32*pngScale, 32*pngScale
+		if opts.Symbology == "code128" {
+			codeW, codeH = pngLabelW-2*pngPadding, 14*pngScale
+		}
+		img, err := Code(asset, opts, codeW, codeH)
+		if err != nil {
+			return err
+		}
+		at := image.Pt(x+pngPadding, y+pngPadding)
+		draw.Draw(sheet, image.Rectangle{Min: at, Max: at.Add(image.Pt(codeW, codeH))}, img, image.Point{}, draw.Src)
+
+		textX, textY, textW := x+pngPadding+codeW+pngPadding/2, y+pngPadding, pngLabelW-codeW-2*pngPadding-pngPadding/2
+		if opts.Symbology == "code128" {
+			textX, textY, textW = x+pngPadding, y+pngPadding+codeH+pngPadding/2, codeW
+		}
+		for j, line := range lines(asset) {
+			drawText(sheet, line, textX, textY+j*pngLineHeight, textW)
+		}
+
+		// cut guide
+		drawBorder(sheet, image.Rect(x, y, x+pngLabelW, y+pngLabelH))
+	}
+
+	return png.Encode(w, sheet)
+}
+
+// drawText renders with the built-in bitmap font, enlarged so it stays readable when printed
+func drawText(dst *image.RGBA, s string, x int, y int, width int) {
+	face := basicfont.Face7x13
+	maxChars := width / (face.Advance * pngTextScale)
+	if len(s) > maxChars {
+		s = s[:max(maxChars, 0)]
+	}
+
+	small := image.NewRGBA(image.Rect(0, 0, len(s)*face.Advance, face.Height))
+	draw.Draw(small, small.Bounds(), image.White, image.Point{}, draw.Src)
+	d := font.Drawer{Dst: small, Src: image.Black, Face: face, Dot: fixed.P(0, face.Ascent)}
+	d.DrawString(s)
+
+	target := image.Rect(x, y, x+small.Bounds().Dx()*pngTextScale, y+small.Bounds().Dy()*pngTextScale)
+	draw.NearestNeighbor.Scale(dst, target, small, small.Bounds(), draw.Src, nil)
+}
+
+func drawBorder(dst *image.RGBA, r image.Rectangle) {
+	grey := color.RGBA{R: 200, G: 200, B: 200, A: 255}
+	for x := r.Min.X; x < r.Max.X; x++ {
+		dst.Set(x, r.Min.Y, grey)
+		dst.Set(x, r.Max.Y-1, grey)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		dst.Set(r.Min.X, y, grey)
+		dst.Set(r.Max.X-1, y, grey)
+	}
+}
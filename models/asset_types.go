@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// SpecField describes one spec attribute of an asset type
+type SpecField struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"` // ENUM: "string", "int", "number", "bool"
+	Required bool     `json:"required,omitempty"`
+	Enum     []string `json:"enum,omitempty"` // allowed values for string fields
+	Min      *float64 `json:"min,omitempty"`  // bounds for int/number fields
+	Max      *float64 `json:"max,omitempty"`
+}
+
+// AssetTypeDefinition is a registry entry: an asset type and the specs it carries
+type AssetTypeDefinition struct {
+	Name      string      `json:"name"`
+	Fields    []SpecField `json:"fields"`
+	BuiltIn   bool        `json:"built_in"`
+	CreatedAt time.Time   `json:"created_at"`
+	DepreciationPolicy
+}
+
+type CreateAssetTypeRequest struct {
+	Name   string      `json:"name"`
+	Fields []SpecField `json:"fields"`
+	DepreciationPolicy
+}
+
+// UpdateAssetTypeRequest replaces the fields of custom types, the depreciation policy can be set on any type
+type UpdateAssetTypeRequest struct {
+	Fields []SpecField `json:"fields"`
+	DepreciationPolicy
+}
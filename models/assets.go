@@ -13,6 +13,35 @@ type ListAssetsResponse struct {
 	AssetType     string `json:"asset_type"`
 	BrandName     string `json:"brand_name"`
 	Status        string `json:"status"`
+
+	// assigned but the employee has not confirmed receipt yet
+	PendingAcknowledgement bool `json:"pending_acknowledgement"`
+
+	// valuation, BookValue is worked out from Cost by the handler, as of ArchivedAt for disposed assets
+	PurchaseCost *float64   `json:"purchase_cost,omitempty"`
+	Currency     string     `json:"currency"`
+	BookValue    *float64   `json:"book_value,omitempty"`
+	Cost         AssetCost  `json:"-"`
+	ArchivedAt   *time.Time `json:"-"`
+
+	// where the active status puts the asset
+	LocationID *string `json:"location_id,omitempty"`
+	Location   *string `json:"location,omitempty"`
+
+	// effective specs, only filled when the listing asks for them
+	Specs map[string]interface{} `json:"specs,omitempty"`
+
+	// sort values of the row, the cursor of a page continuing from it
+	Cursor []string `json:"-"`
+}
+
+// SpecFilter is a typed condition on the effective specs of an asset, e.g. ram_gb>=16
+type SpecFilter struct {
+	Field      string
+	Op         string   // =, !=, >, >=, <, <=, between
+	Values     []string // alternatives for = and !=, the bounds for between
+	Numeric    bool
+	AssetTypes []string // the asset types carrying the field, others never match
 }
 
 type ListAssetsQueryParams struct {
@@ -20,8 +49,16 @@ type ListAssetsQueryParams struct {
 	AssetTypes []string
 	Status     []string
 	OwnedBy    []string
-	Limit      int
-	Offset     int
+	IDs        []string
+	Locations  []string
+	Specs      []SpecFilter
+	SerialNo   string // exact, case-insensitive match as read from a label
+	// IncludeDisposed brings archived (disposed) assets back into the listing
+	IncludeDisposed bool
+	ListParams
+	// IncludeSpecs returns the effective specs with each asset, only SpecFields of them when set
+	IncludeSpecs bool
+	SpecFields   []string
 }
 
 type CreateAssetRequest struct {
@@ -33,6 +70,12 @@ type CreateAssetRequest struct {
 	WarrantyExpDate   *time.Time         `json:"warranty_exp_date"`
 	Specs             interface{}        `json:"specs"` // Raw specs for dynamic routing
 	Status            string             `json:"status"`
+	PurchaseCost      *float64           `json:"purchase_cost"`
+	Currency          *string            `json:"currency"` // defaults to INR
+	SalvageValue      *float64           `json:"salvage_value"`
+	LocationID        *string            `json:"location_id"`
+	// overrides the asset type policy
+	DepreciationPolicy
 }
 
 type UpdateAssetRequest struct {
@@ -44,6 +87,10 @@ type UpdateAssetRequest struct {
 	WarrantyExpDate   *time.Time          `json:"warranty_exp_date"`
 	Specs             interface{}         `json:"specs"` // Raw specs for dynamic routing
 	Status            *string             `json:"status"`
+	PurchaseCost      *float64            `json:"purchase_cost"`
+	Currency          *string             `json:"currency"`
+	SalvageValue      *float64            `json:"salvage_value"`
+	DepreciationPolicy
 }
 
 type AssetWithModel struct {
@@ -56,17 +103,186 @@ type AssetWithModel struct {
 	WarrantyStartDate *time.Time
 	WarrantyExpDate   *time.Time
 	AssetType         string
+	PurchaseCost      *float64
+	SalvageValue      float64
 }
 
 type AssignAssetRequest struct {
+	AssetID    string  `json:"asset_id"`
+	UserID     string  `json:"user_id"`
+	LocationID *string `json:"location_id"` // defaults to the remote location
+}
+
+type BulkAssignRequest struct {
+	Items []AssignAssetRequest `json:"items"`
+}
+
+type BulkRetrieveRequest struct {
+	AssetIDs   []string `json:"asset_ids"`
+	LocationID *string  `json:"location_id"` // where every retrieved asset is stocked
+}
+
+type BulkItemResult struct {
 	AssetID string `json:"asset_id"`
-	UserID  string `json:"user_id"`
+	UserID  string `json:"user_id,omitempty"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkResult reports every item, Applied is false when any item failed and nothing was changed
+type BulkResult struct {
+	Applied bool             `json:"applied"`
+	Results []BulkItemResult `json:"results"`
+}
+
+type TransferAssetRequest struct {
+	AssetID    string  `json:"asset_id"`
+	ToUserID   string  `json:"to_user_id"`
+	Reason     string  `json:"reason"`
+	LocationID *string `json:"location_id"`
 }
 
 type AssetTimeline struct {
-	Status         string     `json:"status"`
-	AssignedToUser *string    `json:"assigned_to_user,omitempty"`
-	SentToService  *string    `json:"sent_to_service,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
-	ArchivedAt     *time.Time `json:"archived_at,omitempty"`
+	Status          string     `json:"status"`
+	AssignedToUser  *string    `json:"assigned_to_user,omitempty"`
+	SentToService   *string    `json:"sent_to_service,omitempty"`
+	Remarks         *string    `json:"remarks,omitempty"`
+	RepairOutcome   *string    `json:"repair_outcome,omitempty"`
+	TransferredFrom *string    `json:"transferred_from,omitempty"`
+	TransferReason  *string    `json:"transfer_reason,omitempty"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at,omitempty"`
+	Signature       *string    `json:"acknowledgement_signature,omitempty"`
+	LocationID      *string    `json:"location_id,omitempty"`
+	Location        *string    `json:"location,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ArchivedAt      *time.Time `json:"archived_at,omitempty"`
+}
+
+type AssetStatus struct {
+	ID             string
+	AssetID        string
+	Status         string
+	AssignedToUser *string
+	SentToService  *string
+	CreatedAt      time.Time
+}
+
+type AssetStatusChangeRequest struct {
+	Remarks    *string `json:"remarks"`
+	LocationID *string `json:"location_id"`
+}
+
+type SendToServiceRequest struct {
+	AssetID   string  `json:"asset_id"`
+	ServiceID string  `json:"service_id"`
+	Remarks   *string `json:"remarks"`
+}
+
+type ReceiveFromServiceRequest struct {
+	Outcome    string  `json:"outcome"` // ENUM: "repaired", "unrepairable"
+	Remarks    *string `json:"remarks"`
+	LocationID *string `json:"location_id"`
+}
+
+type DisposeAssetRequest struct {
+	Reason         string   `json:"reason"` // ENUM: "e_waste", "sold", "lost", "stolen", "returned_to_client"
+	ApprovedBy     string   `json:"approved_by"`
+	SaleValue      *float64 `json:"sale_value"`
+	CertificateRef *string  `json:"certificate_ref"`
+	Remarks        *string  `json:"remarks"`
+}
+
+type AssetDisposal struct {
+	ID             string    `json:"id"`
+	AssetID        string    `json:"asset_id"`
+	SerialNo       string    `json:"serial_no"`
+	ModelName      string    `json:"model_name"`
+	BrandName      string    `json:"brand_name"`
+	Reason         string    `json:"reason"`
+	ApprovedBy     string    `json:"approved_by"`
+	ApproverName   string    `json:"approver_name"`
+	SaleValue      *float64  `json:"sale_value,omitempty"`
+	CertificateRef *string   `json:"certificate_ref,omitempty"`
+	Remarks        *string   `json:"remarks,omitempty"`
+	CreatedBy      string    `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type AssetBrand struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type AssetModel struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	AssetType string `json:"asset_type"`
+}
+
+type AssetCurrentStatus struct {
+	StatusID          string    `json:"status_id"`
+	Status            string    `json:"status"`
+	AssignedToUser    *string   `json:"assigned_to_user,omitempty"`
+	AssignedUserName  *string   `json:"assigned_user_name,omitempty"`
+	AssignedUserEmail *string   `json:"assigned_user_email,omitempty"`
+	SentToService     *string   `json:"sent_to_service,omitempty"`
+	ServiceName       *string   `json:"service_name,omitempty"`
+	Remarks           *string   `json:"remarks,omitempty"`
+	LocationID        *string   `json:"location_id,omitempty"`
+	Location          *string   `json:"location,omitempty"`
+	Since             time.Time `json:"since"`
+
+	// nil until the employee confirms receipt of an assigned asset
+	AcknowledgedAt         *time.Time `json:"acknowledged_at,omitempty"`
+	PendingAcknowledgement bool       `json:"pending_acknowledgement"`
+}
+
+type WarrantyState struct {
+	Status        string `json:"status"` // "active", "expired", "not_started", "unknown"
+	DaysRemaining *int   `json:"days_remaining,omitempty"`
+}
+
+type AssetDetail struct {
+	ID                string              `json:"id"`
+	SerialNo          string              `json:"serial_no"`
+	OwnedBy           string              `json:"owned_by"`
+	PurchasedDate     time.Time           `json:"purchased_date"`
+	WarrantyStartDate *time.Time          `json:"warranty_start_date"`
+	WarrantyExpDate   *time.Time          `json:"warranty_exp_date"`
+	Warranty          WarrantyState       `json:"warranty"`
+	Brand             AssetBrand          `json:"brand"`
+	Model             AssetModel          `json:"model"`
+	SpecsID           string              `json:"-"`
+	Specs             interface{}         `json:"specs"`
+	CurrentStatus     *AssetCurrentStatus `json:"current_status"`
+	Depreciation      *Depreciation       `json:"depreciation,omitempty"`
+	Cost              AssetCost           `json:"-"`
+	CreatedAt         time.Time           `json:"created_at"`
+	ArchivedAt        *time.Time          `json:"archived_at,omitempty"`
+
+	// specs is the effective document, the model defaults and where each value comes from
+	// (inherited, overridden or own) are listed next to it
+	ModelSpecs  map[string]interface{} `json:"model_specs"`
+	SpecSources map[string]string      `json:"spec_sources"`
+}
+
+type AssetExportRow struct {
+	ID                string                 `json:"id"`
+	SerialNo          string                 `json:"serial_no"`
+	OwnedBy           string                 `json:"owned_by"`
+	PurchasedDate     time.Time              `json:"purchased_date"`
+	WarrantyStartDate *time.Time             `json:"warranty_start_date"`
+	WarrantyExpDate   *time.Time             `json:"warranty_exp_date"`
+	BrandName         string                 `json:"brand_name"`
+	ModelName         string                 `json:"model_name"`
+	AssetType         string                 `json:"asset_type"`
+	Status            *string                `json:"status"`
+	AssignedUserName  *string                `json:"assigned_user_name"`
+	AssignedUserEmail *string                `json:"assigned_user_email"`
+	StatusSince       *time.Time             `json:"status_since"`
+	LocationPath      *string                `json:"location"`
+	PurchaseCost      *float64               `json:"purchase_cost"`
+	Currency          string                 `json:"currency"`
+	SalvageValue      float64                `json:"salvage_value"`
+	Specs             map[string]interface{} `json:"specs"`
 }
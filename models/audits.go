@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+type CreateAuditRequest struct {
+	Name       string   `json:"name"`
+	AssetTypes []string `json:"asset_types"` // empty means every type
+	OwnedBy    []string `json:"owned_by"`    // empty means every owner
+}
+
+type AuditScanRequest struct {
+	Code         string  `json:"code"` // asset id or serial number read from the label
+	Location     *string `json:"location"`
+	Condition    string  `json:"condition"`      // ENUM: "good", "damaged", "not_working"
+	SeenWithUser *string `json:"seen_with_user"` // empty when the asset was found in storage
+	Remarks      *string `json:"remarks"`
+}
+
+type Audit struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	AssetTypes []string   `json:"asset_types"`
+	OwnedBy    []string   `json:"owned_by"`
+	Status     string     `json:"status"` // ENUM: "open", "closed"
+	Expected   int        `json:"expected"`
+	Scanned    int        `json:"scanned"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+}
+
+type AuditScan struct {
+	AuditID        string    `json:"audit_id"`
+	AuditName      string    `json:"audit_name,omitempty"`
+	AssetID        *string   `json:"asset_id"`
+	SerialNo       *string   `json:"serial_no,omitempty"`
+	BrandName      *string   `json:"brand_name,omitempty"`
+	ModelName      *string   `json:"model_name,omitempty"`
+	Code           string    `json:"code"`
+	Location       *string   `json:"location,omitempty"`
+	Condition      string    `json:"condition"`
+	SeenWithUser   *string   `json:"seen_with_user,omitempty"`
+	RecordedStatus *string   `json:"recorded_status,omitempty"`
+	RecordedUser   *string   `json:"recorded_user,omitempty"`
+	Remarks        *string   `json:"remarks,omitempty"`
+	ScannedAt      time.Time `json:"scanned_at"`
+}
+
+type AuditExpectedAsset struct {
+	AssetID        string  `json:"asset_id"`
+	SerialNo       string  `json:"serial_no"`
+	BrandName      string  `json:"brand_name"`
+	ModelName      string  `json:"model_name"`
+	Status         *string `json:"status"`
+	AssignedToUser *string `json:"assigned_to_user,omitempty"`
+}
+
+// AuditReport reconciles what was seen with what the audit expected
+type AuditReport struct {
+	Audit            Audit                `json:"audit"`
+	Found            []AuditScan          `json:"found"`
+	Missing          []AuditExpectedAsset `json:"missing"`
+	Unexpected       []AuditScan          `json:"unexpected"`        // out of scope or unknown codes
+	HolderMismatches []AuditScan          `json:"holder_mismatches"` // seen with someone other than the active holder
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+type Brand struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	ModelCount int       `json:"model_count"`
+	AssetCount int       `json:"asset_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Model struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	BrandID    string    `json:"brand_id"`
+	BrandName  string    `json:"brand_name"`
+	AssetType  string    `json:"asset_type"`
+	AssetCount int       `json:"asset_count"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// inherited by every asset of the model unless the asset overrides the value
+	DefaultSpecs map[string]interface{} `json:"default_specs"`
+}
+
+type ListModelsQueryParams struct {
+	Search     string
+	BrandIDs   []string
+	AssetTypes []string
+}
+
+type UpdateBrandRequest struct {
+	Name string `json:"name"`
+}
+
+type CatalogModelRequest struct {
+	Name         string                 `json:"name"`
+	BrandID      string                 `json:"brand_id"`
+	AssetType    string                 `json:"asset_type"`
+	DefaultSpecs map[string]interface{} `json:"default_specs"`
+}
+
+type UpdateModelRequest struct {
+	Name      *string `json:"name"`
+	BrandID   *string `json:"brand_id"`
+	AssetType *string `json:"asset_type"` // only while no asset uses the model
+
+	// merged into the current defaults, a null value removes the default
+	DefaultSpecs map[string]interface{} `json:"default_specs"`
+}
+
+// MergeRequest folds the brand or model of the URL into the one named here
+type MergeRequest struct {
+	IntoID string `json:"into_id"`
+}
+
+type MergeResult struct {
+	IntoID          string `json:"into_id"`
+	MergedModels    int64  `json:"merged_models,omitempty"`
+	MovedModels     int64  `json:"moved_models,omitempty"`
+	RepointedAssets int64  `json:"repointed_assets"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// DepreciationPolicy is how an asset loses value, set per asset type and optionally per asset
+type DepreciationPolicy struct {
+	Method           *string  `json:"depreciation_method"` // ENUM: "straight_line", "declining_balance"
+	UsefulLifeMonths *int     `json:"useful_life_months"`
+	DecliningRate    *float64 `json:"declining_rate,omitempty"` // annual rate, empty means double declining
+}
+
+// AssetCost holds what is needed to value an asset, the policy is already resolved against its type
+type AssetCost struct {
+	PurchaseCost  *float64
+	Currency      string
+	SalvageValue  float64
+	PurchasedDate time.Time
+	Policy        DepreciationPolicy
+}
+
+type Depreciation struct {
+	DepreciationPolicy
+	PurchaseCost            float64 `json:"purchase_cost"`
+	Currency                string  `json:"currency"`
+	SalvageValue            float64 `json:"salvage_value"`
+	BookValue               float64 `json:"book_value"`
+	AccumulatedDepreciation float64 `json:"accumulated_depreciation"`
+}
+
+type DepreciationScheduleRow struct {
+	AssetID       string     `json:"asset_id"`
+	SerialNo      string     `json:"serial_no"`
+	BrandName     string     `json:"brand_name"`
+	ModelName     string     `json:"model_name"`
+	AssetType     string     `json:"asset_type"`
+	PurchasedDate time.Time  `json:"purchased_date"`
+	PurchaseCost  float64    `json:"purchase_cost"`
+	Currency      string     `json:"currency"`
+	Method        string     `json:"depreciation_method"`
+	FiscalYear    string     `json:"fiscal_year"` // e.g. "2025-26"
+	OpeningValue  float64    `json:"opening_value"`
+	Depreciation  float64    `json:"depreciation"`
+	ClosingValue  float64    `json:"closing_value"`
+	DisposedAt    *time.Time `json:"disposed_at,omitempty"`
+}
+
+// DepreciableAsset is an asset with a purchase cost as read for the schedule report
+type DepreciableAsset struct {
+	AssetID    string
+	SerialNo   string
+	BrandName  string
+	ModelName  string
+	AssetType  string
+	ArchivedAt *time.Time
+	Cost       AssetCost
+}
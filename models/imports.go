@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type ImportRowError struct {
+	Row      int      `json:"row"` // line in the file, the header is row 1
+	SerialNo string   `json:"serial_no,omitempty"`
+	Errors   []string `json:"errors"`
+}
+
+type ImportReport struct {
+	ImportID     *string          `json:"import_id,omitempty"`
+	DryRun       bool             `json:"dry_run"`
+	TotalRows    int              `json:"total_rows"`
+	SkippedRows  int              `json:"skipped_rows"` // already imported by an earlier run
+	ValidRows    int              `json:"valid_rows"`
+	InvalidRows  int              `json:"invalid_rows"`
+	ImportedRows int              `json:"imported_rows"`
+	Status       string           `json:"status"`
+	Error        string           `json:"error,omitempty"`
+	RowErrors    []ImportRowError `json:"row_errors"`
+}
+
+type AssetImport struct {
+	ID            string     `json:"id"`
+	FileName      string     `json:"file_name"`
+	Checksum      string     `json:"-"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	Status        string     `json:"status"`
+	LastError     *string    `json:"last_error,omitempty"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// KitItem is one line of a kit, e.g. a laptop with at least 16GB of RAM
+type KitItem struct {
+	AssetType string                 `json:"asset_type"`
+	Brand     *string                `json:"brand,omitempty"`
+	Model     *string                `json:"model,omitempty"`
+	MinSpecs  map[string]interface{} `json:"min_specs,omitempty"` // numeric fields are minimums, other fields must match
+	Quantity  int                    `json:"quantity"`
+}
+
+type OnboardingKit struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	UserType  string    `json:"user_type"` // ENUM: "full_time", "intern", "freelancer"
+	Items     []KitItem `json:"items"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateKitRequest struct {
+	Name     string    `json:"name"`
+	UserType string    `json:"user_type"`
+	Items    []KitItem `json:"items"`
+}
+
+// UpdateKitRequest changes the fields that are set, items replace the existing lines
+type UpdateKitRequest struct {
+	Name     *string   `json:"name"`
+	UserType *string   `json:"user_type"`
+	Items    []KitItem `json:"items"`
+}
+
+type AssignKitRequest struct {
+	UserID string  `json:"user_id"`
+	KitID  *string `json:"kit_id"` // defaults to the kit of the user's user_type
+}
+
+type KitLineResult struct {
+	KitItem
+	AssetIDs []string `json:"asset_ids"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type KitAssignmentResult struct {
+	KitID   string          `json:"kit_id"`
+	KitName string          `json:"kit_name"`
+	UserID  string          `json:"user_id"`
+	DryRun  bool            `json:"dry_run"`
+	Applied bool            `json:"applied"`
+	Lines   []KitLineResult `json:"lines"`
+}
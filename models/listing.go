@@ -0,0 +1,52 @@
+package models
+
+// SortField is a column a listing can be sorted on, Type is the SQL type cursor values are cast back to
+type SortField struct {
+	Name   string
+	Column string
+	Type   string
+}
+
+type SortKey struct {
+	SortField
+	Desc bool
+}
+
+// ListParams is the sorting and paging shared by list endpoints. A cursor continues from the row
+// whose sort values are in After, Page and Offset are the page based fallback.
+type ListParams struct {
+	Sort     []SortKey
+	Limit    int
+	Page     int // 0 when walking by cursor
+	Offset   int
+	After    []string // sort values of the cursor row, its id last
+	Backward bool     // the cursor walks to the previous page
+}
+
+// ListPage is the envelope list endpoints respond with
+type ListPage struct {
+	Items interface{} `json:"items"`
+	Total int         `json:"total"`
+	Limit int         `json:"limit"`
+	Links PageLinks   `json:"links"`
+
+	// counts per value of the asked facets, under the filters of the listing
+	Facets map[string][]FacetCount `json:"facets,omitempty"`
+}
+
+// FacetField is a column a listing can count its rows by, Join brings in the table it lives on
+type FacetField struct {
+	Name   string
+	Column string
+	Join   string
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type PageLinks struct {
+	Next *string `json:"next"`
+	Prev *string `json:"prev"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type CreateLocationRequest struct {
+	Name     string  `json:"name"`
+	Kind     string  `json:"kind"`      // ENUM: "office", "floor", "room", "cabinet"
+	ParentID *string `json:"parent_id"` // required below office level
+	City     *string `json:"city"`      // offices only
+}
+
+type UpdateLocationRequest struct {
+	Name *string `json:"name"`
+	City *string `json:"city"`
+}
+
+type Location struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	ParentID  *string   `json:"parent_id,omitempty"`
+	Path      string    `json:"path"` // "Pune HQ / Floor 2 / Room 204"
+	OfficeID  *string   `json:"office_id,omitempty"`
+	City      *string   `json:"city,omitempty"`
+	Assets    int       `json:"assets"` // with this location or one below it on their active status
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MoveAssetRequest records where an asset now is without changing its status
+type MoveAssetRequest struct {
+	LocationID string `json:"location_id"`
+}
+
+type RetrieveAssetRequest struct {
+	LocationID *string `json:"location_id"` // defaults to where the asset was last stocked
+}
+
+type OfficeStock struct {
+	OfficeID   string         `json:"office_id"`
+	OfficeName string         `json:"office_name"`
+	City       *string        `json:"city,omitempty"`
+	Available  int            `json:"available"`
+	ByType     map[string]int `json:"by_type"`
+}
@@ -22,6 +22,7 @@ type ListUsersResponse struct {
 	Roles              []string `json:"roles"`
 	UserType           string   `json:"user_type"`
 	AssignedAssetCount int      `json:"assigned_asset_count"`
+	Cursor             []string `json:"-"`
 }
 
 type UpdateUserRequest struct {
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type CreateOffboardingRequest struct {
+	UserID          string `json:"user_id"`
+	LastWorkingDate string `json:"last_working_date"` // YYYY-MM-DD
+}
+
+type ClearOffboardingItemRequest struct {
+	Status     string  `json:"status"` // ENUM: "returned", "lost"
+	Remarks    *string `json:"remarks"`
+	LocationID *string `json:"location_id"` // where a returned asset is stocked
+}
+
+type Offboarding struct {
+	ID              string            `json:"id"`
+	UserID          string            `json:"user_id"`
+	UserName        string            `json:"user_name"`
+	UserEmail       string            `json:"user_email"`
+	LastWorkingDate time.Time         `json:"last_working_date"`
+	Status          string            `json:"status"` // ENUM: "open", "completed", "cancelled"
+	PendingItems    int               `json:"pending_items"`
+	CreatedAt       time.Time         `json:"created_at"`
+	ClosedAt        *time.Time        `json:"closed_at,omitempty"`
+	Items           []OffboardingItem `json:"items,omitempty"`
+}
+
+type OffboardingItem struct {
+	AssetID   string     `json:"asset_id"`
+	SerialNo  string     `json:"serial_no"`
+	BrandName string     `json:"brand_name"`
+	ModelName string     `json:"model_name"`
+	Status    string     `json:"status"` // ENUM: "pending", "returned", "lost"
+	Remarks   *string    `json:"remarks,omitempty"`
+	ClearedAt *time.Time `json:"cleared_at,omitempty"`
+	ClearedBy *string    `json:"cleared_by,omitempty"`
+}
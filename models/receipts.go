@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// ReceiptTemplate is the organisation's wording of one kind of receipt.
+// Title, Intro and Footer are text/template strings executed against a Receipt.
+type ReceiptTemplate struct {
+	Kind         string     `json:"kind"` // ENUM: "handover", "return", "statement"
+	Organisation string     `json:"organisation"`
+	Title        string     `json:"title"`
+	Intro        string     `json:"intro"`
+	Footer       string     `json:"footer"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+type UpdateReceiptTemplateRequest struct {
+	Organisation *string `json:"organisation"`
+	Title        *string `json:"title"`
+	Intro        *string `json:"intro"`
+	Footer       *string `json:"footer"`
+}
+
+type Receipt struct {
+	Kind           string
+	Number         string // the asset_status row, or the user for statements
+	Organisation   string
+	EmployeeName   string
+	EmployeeEmail  string
+	Date           time.Time // handover, return or statement date
+	AcknowledgedAt *time.Time
+	Signature      *string
+	Assets         []ReceiptAsset
+}
+
+type ReceiptAsset struct {
+	AssetID    string
+	SerialNo   string
+	BrandName  string
+	ModelName  string
+	AssetType  string
+	Specs      map[string]interface{}
+	Condition  *string // remarks at handover, or the status the asset was returned into
+	AssignedAt time.Time
+	ReturnedAt *time.Time
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// CreateAssetReportRequest is read from the multipart form, photos are sent as "photos" files
+type CreateAssetReportRequest struct {
+	AssetID     string
+	Type        string // ENUM: "damaged", "lost", "stolen", "return"
+	Description string
+	ReturnDate  *time.Time
+	Photos      []AssetReportPhotoUpload
+}
+
+type AssetReportPhotoUpload struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+type ResolveAssetReportRequest struct {
+	Remarks *string `json:"remarks"`
+}
+
+type AssetReportFilterParams struct {
+	ReportedBy string // set for employees so they only see their own reports
+	Types      []string
+	Status     []string
+	ListParams
+}
+
+type AssetReport struct {
+	ID                string             `json:"id"`
+	AssetID           string             `json:"asset_id"`
+	SerialNo          string             `json:"serial_no"`
+	ModelName         string             `json:"model_name"`
+	BrandName         string             `json:"brand_name"`
+	ReportedBy        string             `json:"reported_by"`
+	ReporterName      string             `json:"reporter_name"`
+	Type              string             `json:"type"`
+	Description       string             `json:"description"`
+	ReturnDate        *time.Time         `json:"return_date,omitempty"`
+	Status            string             `json:"status"` // ENUM: "open", "accepted", "rejected"
+	ResolutionRemarks *string            `json:"resolution_remarks,omitempty"`
+	ResolvedBy        *string            `json:"resolved_by,omitempty"`
+	ResolvedAt        *time.Time         `json:"resolved_at,omitempty"`
+	CreatedAt         time.Time          `json:"created_at"`
+	Photos            []AssetReportPhoto `json:"photos,omitempty"`
+
+	// sort values of the row, the cursor of a page continuing from it
+	Cursor []string `json:"-"`
+}
+
+type AssetReportPhoto struct {
+	ID          string    `json:"id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int       `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type CreateAssetRequestRequest struct {
+	AssetType   *string `json:"asset_type"`
+	Description string  `json:"description"` // e.g. "need a 27-inch monitor"
+}
+
+type AssetRequestActionRequest struct {
+	Comment *string `json:"comment"`
+}
+
+type FulfilAssetRequestRequest struct {
+	AssetID string  `json:"asset_id"`
+	Comment *string `json:"comment"`
+}
+
+type AssetRequestFilterParams struct {
+	RequestedBy string // set for employees so they only see their own requests
+	Status      []string
+	AssetTypes  []string
+	ListParams
+}
+
+type AssetRequest struct {
+	ID               string              `json:"id"`
+	RequestedBy      string              `json:"requested_by"`
+	RequesterName    string              `json:"requester_name"`
+	RequesterEmail   string              `json:"requester_email"`
+	AssetType        *string             `json:"asset_type,omitempty"`
+	Description      string              `json:"description"`
+	Status           string              `json:"status"` // ENUM: "pending", "approved", "rejected", "fulfilled", "cancelled"
+	FulfilledAssetID *string             `json:"fulfilled_asset_id,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        *time.Time          `json:"updated_at,omitempty"`
+	History          []AssetRequestEvent `json:"history,omitempty"`
+
+	// sort values of the row, the cursor of a page continuing from it
+	Cursor []string `json:"-"`
+}
+
+type AssetRequestEvent struct {
+	ActorID   string    `json:"actor_id"`
+	ActorName string    `json:"actor_name"`
+	Status    *string   `json:"status,omitempty"` // empty for comments
+	Comment   *string   `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+type Service struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Email    string  `json:"email"`
+	Phone    string  `json:"phone"`
+	Location *string `json:"location"`
+}
+
+type UpdateServiceRequest struct {
+	Name     *string `json:"name"`
+	Email    *string `json:"email"`
+	Phone    *string `json:"phone"`
+	Location *string `json:"location"`
+}
+
+type ServiceFilterParams struct {
+	Search string `json:"search"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type ListServicesResponse struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Email            string    `json:"email"`
+	Phone            string    `json:"phone"`
+	Location         *string   `json:"location"`
+	ActiveAssetCount int       `json:"active_asset_count"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type ServiceStats struct {
+	Service
+	ActiveAssetCount   int      `json:"active_asset_count"`
+	CompletedJobCount  int      `json:"completed_job_count"`
+	AvgTurnaroundHours *float64 `json:"avg_turnaround_hours"`
+	RepairedCount      int      `json:"repaired_count"`
+	UnrepairableCount  int      `json:"unrepairable_count"`
+}
+
+type ServiceJob struct {
+	StatusID      string     `json:"status_id"`
+	AssetID       string     `json:"asset_id"`
+	SerialNo      string     `json:"serial_no"`
+	ModelName     string     `json:"model_name"`
+	BrandName     string     `json:"brand_name"`
+	AssetType     string     `json:"asset_type"`
+	Remarks       *string    `json:"remarks,omitempty"`
+	RepairOutcome *string    `json:"repair_outcome,omitempty"`
+	SentAt        time.Time  `json:"sent_at"`
+	ReturnedAt    *time.Time `json:"returned_at,omitempty"`
+}
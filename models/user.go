@@ -3,12 +3,11 @@ package models
 import "time"
 
 type UserFilterParams struct {
-	Search      string   `json "search"`
-	UserTypes   []string `json "user_type"`
-	Roles       []string `json "role"`
-	AssetStatus []string `json "asset_status"`
-	Limit       int      `json "limit"`
-	Offset      int      `json "offset"`
+	Search      string   `json:"search"`
+	UserTypes   []string `json:"user_type"`
+	Roles       []string `json:"role"`
+	AssetStatus []string `json:"asset_status"`
+	ListParams
 }
 
 type AssignedAsset struct {
@@ -17,6 +16,9 @@ type AssignedAsset struct {
 	BrandName  string    `json:"brand_name"`
 	Status     string    `json:"status"`
 	AssignedAt time.Time `json:"assigned_at"`
+
+	// nil until the employee confirms receipt
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
 }
 
 type UserDetails struct {
@@ -26,5 +28,22 @@ type UserDetails struct {
 	UserType           string          `json:"user_type"`
 	Roles              []string        `json:"roles"`
 	AssignedAssetCount int             `json:"asset_status"`
+	PendingHandovers   int             `json:"pending_handovers"`
 	AssignedAssets     []AssignedAsset `json:"assigned_assets"`
 }
+
+type AcknowledgeHandoverRequest struct {
+	Signature string `json:"signature"` // the employee's full name, typed
+}
+
+type PendingHandover struct {
+	AssetID    string    `json:"asset_id"`
+	SerialNo   string    `json:"serial_no"`
+	BrandName  string    `json:"brand_name"`
+	ModelName  string    `json:"model_name"`
+	UserID     string    `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	UserEmail  string    `json:"user_email"`
+	AssignedAt time.Time `json:"assigned_at"`
+	DaysOpen   int       `json:"days_open"`
+}
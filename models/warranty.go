@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type WarrantyAsset struct {
+	AssetID          string    `json:"asset_id"`
+	SerialNo         string    `json:"serial_no"`
+	BrandName        string    `json:"brand_name"`
+	ModelName        string    `json:"model_name"`
+	AssetType        string    `json:"asset_type"`
+	WarrantyExpDate  time.Time `json:"warranty_exp_date"`
+	AssignedUserName *string   `json:"assigned_user_name,omitempty"`
+}
+
+type WarrantyMonth struct {
+	Month  string          `json:"month"` // YYYY-MM
+	Count  int             `json:"count"`
+	Assets []WarrantyAsset `json:"assets"`
+}
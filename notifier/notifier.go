@@ -0,0 +1,48 @@
+package notifier
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to people, implementations must be safe for concurrent use
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier writes messages to the server log, used when no mail server is configured
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, msg Message) error {
+	log.Printf("notification to %s: %s\n%s", strings.Join(msg.To, ", "), msg.Subject, msg.Body)
+	return nil
+}
+
+// FromEnv returns an SMTP notifier when SMTP_HOST is set and a LogNotifier otherwise
+func FromEnv() Notifier {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return LogNotifier{}
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "25"
+	}
+
+	return &SMTPNotifier{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USER"),
+		Password: os.Getenv("SMTP_PASS"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+}
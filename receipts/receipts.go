@@ -0,0 +1,173 @@
+package receipts
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jung-kurt/gofpdf"
+	"io"
+	"maps"
+	"slices"
+	"storex/models"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var ErrInvalidTemplate = errors.New("invalid receipt template")
+
+const dateLayout = "02 Jan 2006"
+
+// text is the executed wording of a template
+type text struct {
+	title, intro, footer string
+}
+
+// Validate executes the template against a sample receipt so broken placeholders are caught when saved
+func Validate(tpl *models.ReceiptTemplate) error {
+	returned := time.Now()
+	_, err := execute(tpl, &models.Receipt{
+		Kind:          tpl.Kind,
+		Organisation:  tpl.Organisation,
+		EmployeeName:  "Jane Doe",
+		EmployeeEmail: "jane@example.com",
+		Date:          time.Now(),
+		Assets: []models.ReceiptAsset{{
+			BrandName:  "Dell",
+			ModelName:  "Latitude 5440",
+			AssetType:  "laptop",
+			SerialNo:   "SN-0001",
+			AssignedAt: time.Now(),
+			ReturnedAt: &returned,
+		}},
+	})
+	return err
+}
+
+func execute(tpl *models.ReceiptTemplate, receipt *models.Receipt) (text, error) {
+	var out text
+	parts := []struct {
+		name string
+		src  string
+		dst  *string
+	}{
+		{"title", tpl.Title, &out.title},
+		{"intro", tpl.Intro, &out.intro},
+		{"footer", tpl.Footer, &out.footer},
+	}
+
+	for _, part := range parts {
+		t, err := template.New(part.name).Option("missingkey=error").Parse(part.src)
+		if err != nil {
+			return out, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, part.name, err)
+		}
+		var b strings.Builder
+		if err := t.Execute(&b, receipt); err != nil {
+			return out, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, part.name, err)
+		}
+		*part.dst = b.String()
+	}
+	return out, nil
+}
+
+// Render writes the receipt as an A4 PDF worded by the organisation's template
+func Render(w io.Writer, tpl *models.ReceiptTemplate, receipt *models.Receipt) error {
+	receipt.Organisation = tpl.Organisation
+	words, err := execute(tpl, receipt)
+	if err != nil {
+		return err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	tr := pdf.UnicodeTranslatorFromDescriptor("") // core fonts are cp1252
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 8, tr(tpl.Organisation), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, tr(words.title), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.CellFormat(0, 5, "Receipt no. "+receipt.Number, "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, "Issued "+time.Now().Format(dateLayout), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	if words.intro != "" {
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.MultiCell(0, 6, tr(words.intro), "", "L", false)
+		pdf.Ln(3)
+	}
+
+	field(pdf, tr, "Employee", receipt.EmployeeName)
+	field(pdf, tr, "Email", receipt.EmployeeEmail)
+	pdf.Ln(3)
+
+	if len(receipt.Assets) == 0 {
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.CellFormat(0, 6, "No assets.", "", 1, "L", false, 0, "")
+	}
+	for i, asset := range receipt.Assets {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetFillColor(235, 235, 235)
+		heading := fmt.Sprintf("%d. %s %s (%s)", i+1, asset.BrandName, asset.ModelName, asset.AssetType)
+		pdf.CellFormat(0, 7, tr(heading), "", 1, "L", true, 0, "")
+
+		field(pdf, tr, "Serial no.", asset.SerialNo)
+		field(pdf, tr, "Asset id", asset.AssetID)
+		field(pdf, tr, "Handed over", asset.AssignedAt.Format(dateLayout))
+		if asset.ReturnedAt != nil {
+			field(pdf, tr, "Returned", asset.ReturnedAt.Format(dateLayout))
+		}
+		if asset.Condition != nil {
+			field(pdf, tr, "Condition", *asset.Condition)
+		}
+		if len(asset.Specs) > 0 {
+			field(pdf, tr, "Specs", formatSpecs(asset.Specs))
+		}
+		pdf.Ln(3)
+	}
+
+	if receipt.Kind == "handover" {
+		acknowledgement := "Not yet acknowledged by the employee"
+		if receipt.AcknowledgedAt != nil && receipt.Signature != nil {
+			acknowledgement = fmt.Sprintf("Acknowledged on %s, signed \"%s\"",
+				receipt.AcknowledgedAt.Format(dateLayout+" 15:04 MST"), *receipt.Signature)
+		}
+		field(pdf, tr, "Receipt", acknowledgement)
+		pdf.Ln(3)
+	}
+
+	if words.footer != "" {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 5, tr(words.footer), "", "L", false)
+	}
+
+	// space for wet signatures
+	pdf.Ln(18)
+	pdf.SetFont("Helvetica", "", 10)
+	half := 80.0
+	pdf.CellFormat(half, 5, "Employee signature", "T", 0, "L", false, 0, "")
+	pdf.CellFormat(10, 5, "", "", 0, "L", false, 0, "")
+	pdf.CellFormat(half, 5, "For "+tr(tpl.Organisation), "T", 1, "L", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+func field(pdf *gofpdf.Fpdf, tr func(string) string, label string, value string) {
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(35, 6, label, "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.MultiCell(0, 6, tr(value), "", "L", false)
+}
+
+// formatSpecs lists the specs as "key: value" pairs in key order
+func formatSpecs(specs map[string]interface{}) string {
+	parts := make([]string, 0, len(specs))
+	for _, key := range slices.Sorted(maps.Keys(specs)) {
+		parts = append(parts, fmt.Sprintf("%s: %v", strings.ReplaceAll(key, "_", " "), specs[key]))
+	}
+	return strings.Join(parts, ", ")
+}
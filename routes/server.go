@@ -11,6 +11,10 @@ func Routes(r chi.Router) {
 		AuthRoutes(api)
 		UsersRoutes(api)
 		AssetsRoutes(api)
+		ServicesRoutes(api)
+		RequestsRoutes(api)
+		ReportsRoutes(api)
+		OffboardingRoutes(api)
 	})
 }
 
@@ -28,6 +32,8 @@ func UsersRoutes(r chi.Router) {
 		// Routes needing only AuthMiddleware
 		users.Group(func(authOnly chi.Router) {
 			authOnly.Get("/dashboard", handlers.GetUserDashboard)
+			authOnly.Post("/dashboard/assets/{asset_id}/acknowledge", handlers.AcknowledgeHandover)
+			authOnly.Get("/dashboard/statement", handlers.MyStatement)
 		})
 
 		// Routes needing Auth + Role Middleware
@@ -47,11 +53,193 @@ func AssetsRoutes(r chi.Router) {
 		asset.Use(middleware.RequireRoles("admin", "asset_manager"))
 		asset.Post("/", handlers.CreateAsset)
 		asset.Get("/", handlers.ListAssets)
+		asset.Get("/{id}", handlers.GetAsset)
 		asset.Patch("/{id}", handlers.UpdateAsset)
 		asset.Post("/assign", handlers.AssignAsset)
+		asset.Post("/transfer", handlers.TransferAsset)
+		asset.Get("/handovers/pending", handlers.ListPendingHandovers)
 		asset.Patch("/retrieve/{asset_id}", handlers.RetrieveAsset)
 		asset.Get("/timeline", handlers.AssetTimeline)
 		asset.Get("/user/timeline", handlers.UserAssetTimeline)
+		asset.Get("/warranty", handlers.WarrantyReport)
+		asset.Get("/depreciation", handlers.DepreciationSchedule)
+
+		// bulk assignment / retrieval, all-or-nothing
+		asset.Post("/bulk/assign", handlers.BulkAssignAssets)
+		asset.Post("/bulk/retrieve", handlers.BulkRetrieveAssets)
+
+		// repair / service workflow
+		asset.Patch("/damaged/{asset_id}", handlers.MarkAssetDamaged)
+		asset.Patch("/repair/{asset_id}", handlers.QueueAssetForRepair)
+		asset.Post("/service", handlers.SendAssetToService)
+		asset.Patch("/service/receive/{asset_id}", handlers.ReceiveAssetFromService)
+
+		// disposal / write-off
+		asset.Post("/dispose/{asset_id}", handlers.DisposeAsset)
+		asset.Get("/disposals", handlers.ListAssetDisposals)
+
+		// where assets are, moving one keeps its status
+		asset.Patch("/move/{asset_id}", handlers.MoveAsset)
+		asset.Route("/locations", func(locations chi.Router) {
+			locations.Get("/", handlers.ListLocations)
+			locations.Post("/", handlers.CreateLocation)
+			locations.Get("/stock", handlers.OfficeStock)
+			locations.Get("/{location_id}", handlers.GetLocation)
+			locations.Patch("/{location_id}", handlers.UpdateLocation)
+			locations.Delete("/{location_id}", handlers.DeleteLocation)
+		})
+
+		// printable labels and lookup by scanned code
+		asset.Get("/labels", handlers.PrintLabels)
+		asset.Get("/scan", handlers.ScanAsset)
+
+		// bulk import and inventory export
+		asset.Post("/import", handlers.ImportAssets)
+		asset.Get("/import/{import_id}", handlers.GetAssetImport)
+		asset.Get("/export", handlers.ExportAssets)
+
+		// asset type registry, changes are admin only
+		asset.Route("/types", func(types chi.Router) {
+			types.Get("/", handlers.ListAssetTypes)
+			types.Get("/{name}", handlers.GetAssetType)
+			types.Group(func(adminOnly chi.Router) {
+				adminOnly.Use(middleware.RequireRoles("admin"))
+				adminOnly.Post("/", handlers.CreateAssetType)
+				adminOnly.Patch("/{name}", handlers.UpdateAssetType)
+				adminOnly.Delete("/{name}", handlers.DeleteAssetType)
+			})
+		})
+
+		// brand and model catalog, merging and deleting are admin only
+		asset.Route("/brands", func(brands chi.Router) {
+			brands.Get("/", handlers.ListBrands)
+			brands.Post("/", handlers.CreateBrand)
+			brands.Get("/{brand_id}", handlers.GetBrand)
+			brands.Patch("/{brand_id}", handlers.UpdateBrand)
+			brands.Group(func(adminOnly chi.Router) {
+				adminOnly.Use(middleware.RequireRoles("admin"))
+				adminOnly.Delete("/{brand_id}", handlers.DeleteBrand)
+				adminOnly.Post("/{brand_id}/merge", handlers.MergeBrand)
+			})
+		})
+		asset.Route("/models", func(models chi.Router) {
+			models.Get("/", handlers.ListModels)
+			models.Post("/", handlers.CreateModel)
+			models.Get("/{model_id}", handlers.GetModel)
+			models.Patch("/{model_id}", handlers.UpdateModel)
+			models.Group(func(adminOnly chi.Router) {
+				adminOnly.Use(middleware.RequireRoles("admin"))
+				adminOnly.Delete("/{model_id}", handlers.DeleteModel)
+				adminOnly.Post("/{model_id}/merge", handlers.MergeModel)
+			})
+		})
+
+		// PDF receipts, wording is set per organisation by admins
+		asset.Route("/receipts", func(receipts chi.Router) {
+			receipts.Get("/{status_id}", handlers.StatusReceipt)
+			receipts.Get("/user/{user_id}", handlers.UserStatement)
+			receipts.Get("/templates", handlers.ListReceiptTemplates)
+			receipts.Group(func(adminOnly chi.Router) {
+				adminOnly.Use(middleware.RequireRoles("admin"))
+				adminOnly.Patch("/templates/{kind}", handlers.UpdateReceiptTemplate)
+			})
+		})
+
+		// onboarding kits, assigned to a user in one step
+		asset.Route("/kits", func(kits chi.Router) {
+			kits.Get("/", handlers.ListKits)
+			kits.Post("/", handlers.CreateKit)
+			kits.Post("/assign", handlers.AssignKit)
+			kits.Get("/{kit_id}", handlers.GetKit)
+			kits.Patch("/{kit_id}", handlers.UpdateKit)
+			kits.Delete("/{kit_id}", handlers.DeleteKit)
+		})
+
+		// physical inventory audits, reconciled against the active asset status
+		asset.Route("/audits", func(audits chi.Router) {
+			audits.Get("/", handlers.ListAudits)
+			audits.Post("/", handlers.CreateAudit)
+			audits.Get("/history/{asset_id}", handlers.AssetAuditHistory)
+			audits.Get("/{audit_id}", handlers.GetAudit)
+			audits.Post("/{audit_id}/scans", handlers.ScanAudit)
+			audits.Patch("/{audit_id}/close", handlers.CloseAudit)
+			audits.Get("/{audit_id}/report", handlers.AuditReport)
+		})
 	})
 
 }
+
+func ServicesRoutes(r chi.Router) {
+	r.Route("/services", func(services chi.Router) {
+		services.Use(middleware.AuthMiddleware())
+		services.Use(middleware.RequireRoles("admin", "asset_manager"))
+		services.Get("/", handlers.ListServices)
+		services.Post("/", handlers.CreateService)
+		services.Get("/{service_id}", handlers.GetService)
+		services.Get("/{service_id}/jobs", handlers.ServiceJobs)
+		services.Patch("/{service_id}", handlers.UpdateService)
+		services.Delete("/{service_id}", handlers.DeleteService)
+	})
+}
+
+func RequestsRoutes(r chi.Router) {
+	r.Route("/requests", func(requests chi.Router) {
+		requests.Use(middleware.AuthMiddleware())
+
+		// Employees file and follow their own requests, managers see all of them
+		requests.Group(func(authOnly chi.Router) {
+			authOnly.Post("/", handlers.CreateAssetRequest)
+			authOnly.Get("/", handlers.ListAssetRequests)
+			authOnly.Get("/{request_id}", handlers.GetAssetRequest)
+			authOnly.Post("/{request_id}/comments", handlers.CommentOnAssetRequest)
+			authOnly.Patch("/{request_id}/cancel", handlers.CancelAssetRequest)
+		})
+
+		requests.Group(func(roleRoutes chi.Router) {
+			roleRoutes.Use(middleware.RequireRoles("admin", "asset_manager"))
+			roleRoutes.Patch("/{request_id}/approve", handlers.ApproveAssetRequest)
+			roleRoutes.Patch("/{request_id}/reject", handlers.RejectAssetRequest)
+			roleRoutes.Patch("/{request_id}/fulfil", handlers.FulfilAssetRequest)
+		})
+	})
+}
+
+func ReportsRoutes(r chi.Router) {
+	r.Route("/reports", func(reports chi.Router) {
+		reports.Use(middleware.AuthMiddleware())
+
+		// Employees report problems with and returns of their own assets
+		reports.Group(func(authOnly chi.Router) {
+			authOnly.Post("/", handlers.CreateAssetReport)
+			authOnly.Get("/", handlers.ListAssetReports)
+			authOnly.Get("/{report_id}", handlers.GetAssetReport)
+			authOnly.Get("/{report_id}/photos/{photo_id}", handlers.GetAssetReportPhoto)
+		})
+
+		reports.Group(func(roleRoutes chi.Router) {
+			roleRoutes.Use(middleware.RequireRoles("admin", "asset_manager"))
+			roleRoutes.Patch("/{report_id}/accept", handlers.AcceptAssetReport)
+			roleRoutes.Patch("/{report_id}/reject", handlers.RejectAssetReport)
+		})
+	})
+}
+
+func OffboardingRoutes(r chi.Router) {
+	r.Route("/offboarding", func(offboarding chi.Router) {
+		offboarding.Use(middleware.AuthMiddleware())
+		offboarding.Use(middleware.RequireRoles("admin", "employee_manager", "asset_manager"))
+		offboarding.Get("/", handlers.ListOffboardings)
+		offboarding.Get("/{offboarding_id}", handlers.GetOffboarding)
+
+		// employee managers start and stop the process, asset managers collect the assets
+		offboarding.Group(func(employees chi.Router) {
+			employees.Use(middleware.RequireRoles("admin", "employee_manager"))
+			employees.Post("/", handlers.OpenOffboarding)
+			employees.Patch("/{offboarding_id}/cancel", handlers.CancelOffboarding)
+		})
+		offboarding.Group(func(assets chi.Router) {
+			assets.Use(middleware.RequireRoles("admin", "asset_manager"))
+			assets.Patch("/{offboarding_id}/items/{asset_id}", handlers.ClearOffboardingItem)
+		})
+	})
+}
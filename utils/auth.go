@@ -12,6 +12,14 @@ func IsValidEmail(email string) bool {
 	return re.MatchString(email)
 }
 
+// IsValidContactEmail validates emails of external contacts such as service vendors
+func IsValidContactEmail(email string) bool {
+	pattern := `^[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}$`
+
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(email)
+}
+
 func ExtractNameFromEmail(email string) string {
 	parts := strings.Split(email, "@")
 	if len(parts) > 0 {
@@ -47,3 +55,11 @@ func IsValidPhone(phone string) bool {
 	}
 	return false
 }
+
+// IsValidUUID checks ids before they reach a query, a malformed uuid aborts the whole transaction
+func IsValidUUID(id string) bool {
+	pattern := `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+
+	re := regexp.MustCompile(pattern)
+	return re.MatchString(id)
+}
@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+)
+
+// CatalogStrictMode reads CATALOG_STRICT_MODE. When on, assets may only use brands and models
+// already in the catalog instead of creating them on the fly.
+func CatalogStrictMode() bool {
+	strict, err := strconv.ParseBool(os.Getenv("CATALOG_STRICT_MODE"))
+	return err == nil && strict
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"storex/models"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidDepreciation = errors.New("invalid depreciation")
+
+var depreciationMethods = []string{"straight_line", "declining_balance"}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidateDepreciationPolicy checks the policy fields that are set, unset fields fall back to the asset type
+func ValidateDepreciationPolicy(policy models.DepreciationPolicy) error {
+	if policy.Method != nil && !slices.Contains(depreciationMethods, *policy.Method) {
+		return fmt.Errorf("%w: depreciation_method must be one of %v", ErrInvalidDepreciation, depreciationMethods)
+	}
+	if policy.UsefulLifeMonths != nil && *policy.UsefulLifeMonths <= 0 {
+		return fmt.Errorf("%w: useful_life_months must be positive", ErrInvalidDepreciation)
+	}
+	if policy.DecliningRate != nil && (*policy.DecliningRate <= 0 || *policy.DecliningRate > 1) {
+		return fmt.Errorf("%w: declining_rate must be between 0 and 1", ErrInvalidDepreciation)
+	}
+	return nil
+}
+
+// ValidateAssetCost checks purchase cost, salvage value and an ISO 4217 currency code
+func ValidateAssetCost(cost *float64, salvage *float64, currency *string) error {
+	for _, v := range []*float64{cost, salvage} {
+		if v != nil && (math.IsNaN(*v) || math.IsInf(*v, 0)) {
+			return fmt.Errorf("%w: purchase_cost and salvage_value must be finite numbers", ErrInvalidDepreciation)
+		}
+	}
+	if cost != nil && *cost < 0 {
+		return fmt.Errorf("%w: purchase_cost cannot be negative", ErrInvalidDepreciation)
+	}
+	if salvage != nil && *salvage < 0 {
+		return fmt.Errorf("%w: salvage_value cannot be negative", ErrInvalidDepreciation)
+	}
+	if cost != nil && salvage != nil && *salvage > *cost {
+		return fmt.Errorf("%w: salvage_value cannot exceed purchase_cost", ErrInvalidDepreciation)
+	}
+	if currency != nil && !currencyPattern.MatchString(*currency) {
+		return fmt.Errorf("%w: currency must be a 3 letter code like INR", ErrInvalidDepreciation)
+	}
+	return nil
+}
+
+// Depreciate values an asset at the given time, nil when its purchase cost is unknown.
+// Without a complete policy the asset is carried at cost.
+func Depreciate(cost models.AssetCost, at time.Time) *models.Depreciation {
+	if cost.PurchaseCost == nil {
+		return nil
+	}
+
+	book := BookValue(cost, at)
+	return &models.Depreciation{
+		DepreciationPolicy:      cost.Policy,
+		PurchaseCost:            *cost.PurchaseCost,
+		Currency:                cost.Currency,
+		SalvageValue:            cost.SalvageValue,
+		BookValue:               book,
+		AccumulatedDepreciation: roundMoney(*cost.PurchaseCost - book),
+	}
+}
+
+// BookValue is the purchase cost less depreciation for every full month owned up to at
+func BookValue(cost models.AssetCost, at time.Time) float64 {
+	if cost.PurchaseCost == nil {
+		return 0
+	}
+	purchase := *cost.PurchaseCost
+	salvage := min(cost.SalvageValue, purchase)
+
+	policy := cost.Policy
+	if policy.Method == nil || policy.UsefulLifeMonths == nil || *policy.UsefulLifeMonths <= 0 {
+		return roundMoney(purchase)
+	}
+	life := *policy.UsefulLifeMonths
+	months := min(monthsElapsed(cost.PurchasedDate, at), life)
+
+	switch *policy.Method {
+	case "straight_line":
+		return roundMoney(purchase - (purchase-salvage)*float64(months)/float64(life))
+	case "declining_balance":
+		// double declining by default, switching to straight line once that writes off more
+		rate := min(24/float64(life), 1)
+		if policy.DecliningRate != nil {
+			rate = *policy.DecliningRate
+		}
+
+		value := purchase
+		for m := 0; m < months; m++ {
+			declining := value * rate / 12
+			straight := (value - salvage) / float64(life-m)
+			value -= min(max(declining, straight), value-salvage)
+		}
+		return roundMoney(value)
+	}
+	return roundMoney(purchase)
+}
+
+// FiscalYearStartMonth reads FISCAL_YEAR_START_MONTH (1-12), defaulting to April
+func FiscalYearStartMonth() time.Month {
+	month, err := strconv.Atoi(os.Getenv("FISCAL_YEAR_START_MONTH"))
+	if err != nil || month < 1 || month > 12 {
+		return time.April
+	}
+	return time.Month(month)
+}
+
+// CurrentFiscalYear returns the calendar year in which the fiscal year containing now started
+func CurrentFiscalYear(now time.Time, startMonth time.Month) int {
+	if now.Month() < startMonth {
+		return now.Year() - 1
+	}
+	return now.Year()
+}
+
+// FiscalYear returns the start and end of the fiscal year beginning in startMonth of year
+func FiscalYear(year int, startMonth time.Month) (time.Time, time.Time) {
+	start := time.Date(year, startMonth, 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(1, 0, 0)
+}
+
+// FiscalYearLabel formats a fiscal year like "2025-26", or "2025" when it matches the calendar year
+func FiscalYearLabel(year int, startMonth time.Month) string {
+	if startMonth == time.January {
+		return fmt.Sprint(year)
+	}
+	return fmt.Sprintf("%d-%02d", year, (year+1)%100)
+}
+
+// monthsElapsed counts full months between from and to
+func monthsElapsed(from time.Time, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	return max(months, 0)
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
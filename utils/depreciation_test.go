@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"storex/models"
+	"testing"
+	"time"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var purchased = time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+func assetCost(cost float64, salvage float64, method string, life int) models.AssetCost {
+	return models.AssetCost{
+		PurchaseCost:  &cost,
+		SalvageValue:  salvage,
+		PurchasedDate: purchased,
+		Policy:        models.DepreciationPolicy{Method: &method, UsefulLifeMonths: &life},
+	}
+}
+
+func TestBookValueStraightLine(t *testing.T) {
+	cost := assetCost(1000, 100, "straight_line", 36)
+
+	tests := []struct {
+		months int
+		want   float64
+	}{
+		{0, 1000},
+		{1, 975},
+		{12, 700},
+		{35, 125},
+		{36, 100}, // the final month writes down to salvage
+		{48, 100}, // and it stays there
+	}
+	for _, tt := range tests {
+		if got := BookValue(cost, purchased.AddDate(0, tt.months, 0)); got != tt.want {
+			t.Errorf("after %d months got %v, want %v", tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestBookValueDecliningSwitchesToStraightLine(t *testing.T) {
+	// double declining over 36 months writes off 1/18 a month, straight line on the rest
+	// writes off more once fewer than 18 months are left
+	cost := assetCost(1200, 0, "declining_balance", 36)
+	at := func(months int) float64 { return BookValue(cost, purchased.AddDate(0, months, 0)) }
+
+	for _, m := range []int{1, 12, 18} {
+		want := roundMoney(1200 * math.Pow(17.0/18, float64(m)))
+		if got := at(m); got != want {
+			t.Errorf("declining phase after %d months got %v, want %v", m, got, want)
+		}
+	}
+
+	switched := 1200 * math.Pow(17.0/18, 19)
+	for _, m := range []int{19, 24, 30, 35} {
+		want := roundMoney(switched * float64(36-m) / 17)
+		if got := at(m); got != want {
+			t.Errorf("straight line phase after %d months got %v, want %v", m, got, want)
+		}
+	}
+
+	if got := at(36); got != 0 {
+		t.Errorf("after the useful life got %v, want 0", got)
+	}
+}
+
+func TestBookValueDecliningRate(t *testing.T) {
+	cost := assetCost(1000, 0, "declining_balance", 120)
+	cost.Policy.DecliningRate = ptr(0.12)
+
+	if got, want := BookValue(cost, purchased.AddDate(0, 1, 0)), 990.0; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBookValueSalvageNotBelowCost(t *testing.T) {
+	for _, method := range depreciationMethods {
+		for _, salvage := range []float64{1000, 1500} {
+			cost := assetCost(1000, salvage, method, 24)
+			for _, m := range []int{0, 6, 24, 36} {
+				if got := BookValue(cost, purchased.AddDate(0, m, 0)); got != 1000 {
+					t.Errorf("%s with salvage %v after %d months got %v, want 1000", method, salvage, m, got)
+				}
+			}
+		}
+	}
+}
+
+func TestBookValueWithoutPolicy(t *testing.T) {
+	cost := assetCost(500, 0, "straight_line", 12)
+	cost.Policy.UsefulLifeMonths = nil
+	if got := BookValue(cost, purchased.AddDate(1, 0, 0)); got != 500 {
+		t.Errorf("got %v, want the purchase cost", got)
+	}
+
+	cost.PurchaseCost = nil
+	if d := Depreciate(cost, purchased); d != nil {
+		t.Errorf("got %+v without a purchase cost, want nil", d)
+	}
+}
+
+func TestDepreciate(t *testing.T) {
+	d := Depreciate(assetCost(1000, 100, "straight_line", 36), purchased.AddDate(0, 12, 0))
+	if d.BookValue != 700 || d.AccumulatedDepreciation != 300 {
+		t.Errorf("got book value %v and accumulated %v, want 700 and 300", d.BookValue, d.AccumulatedDepreciation)
+	}
+}
+
+func TestMonthsElapsed(t *testing.T) {
+	tests := []struct {
+		from, to time.Time
+		want     int
+	}{
+		{purchased, purchased, 0},
+		{purchased, purchased.AddDate(0, 0, -10), 0},
+		{purchased, time.Date(2024, time.February, 14, 0, 0, 0, 0, time.UTC), 0},
+		{purchased, time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), 0},
+		{purchased, time.Date(2026, time.March, 20, 0, 0, 0, 0, time.UTC), 26},
+	}
+	for _, tt := range tests {
+		if got := monthsElapsed(tt.from, tt.to); got != tt.want {
+			t.Errorf("monthsElapsed(%s, %s) = %d, want %d", tt.from.Format("2006-01-02"), tt.to.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
+
+func TestValidateAssetCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		cost     *float64
+		salvage  *float64
+		currency *string
+		ok       bool
+	}{
+		{"empty", nil, nil, nil, true},
+		{"valid", ptr(1000.0), ptr(100.0), ptr("INR"), true},
+		{"salvage equal to cost", ptr(1000.0), ptr(1000.0), nil, true},
+		{"salvage above cost", ptr(1000.0), ptr(1000.01), nil, false},
+		{"negative cost", ptr(-1.0), nil, nil, false},
+		{"negative salvage", nil, ptr(-1.0), nil, false},
+		{"NaN cost", ptr(math.NaN()), nil, nil, false},
+		{"infinite cost", ptr(math.Inf(1)), nil, nil, false},
+		{"NaN salvage", ptr(1000.0), ptr(math.NaN()), nil, false},
+		{"lower case currency", nil, nil, ptr("inr"), false},
+		{"long currency", nil, nil, ptr("INRS"), false},
+	}
+	for _, tt := range tests {
+		err := ValidateAssetCost(tt.cost, tt.salvage, tt.currency)
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidDepreciation) {
+			t.Errorf("%s: got %v, want ErrInvalidDepreciation", tt.name, err)
+		}
+	}
+}
+
+func TestValidateDepreciationPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy models.DepreciationPolicy
+		ok     bool
+	}{
+		{"empty", models.DepreciationPolicy{}, true},
+		{"valid", models.DepreciationPolicy{Method: ptr("declining_balance"), UsefulLifeMonths: ptr(36), DecliningRate: ptr(0.4)}, true},
+		{"unknown method", models.DepreciationPolicy{Method: ptr("sum_of_years")}, false},
+		{"zero life", models.DepreciationPolicy{UsefulLifeMonths: ptr(0)}, false},
+		{"zero rate", models.DepreciationPolicy{DecliningRate: ptr(0.0)}, false},
+		{"rate above one", models.DepreciationPolicy{DecliningRate: ptr(1.5)}, false},
+	}
+	for _, tt := range tests {
+		err := ValidateDepreciationPolicy(tt.policy)
+		if tt.ok != (err == nil) {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+}
+
+func TestFiscalYear(t *testing.T) {
+	if got := CurrentFiscalYear(time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC), time.April); got != 2024 {
+		t.Errorf("March 2025 with an April start got %d, want 2024", got)
+	}
+	if got := CurrentFiscalYear(time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC), time.April); got != 2025 {
+		t.Errorf("April 2025 with an April start got %d, want 2025", got)
+	}
+
+	start, end := FiscalYear(2025, time.April)
+	if !start.Equal(time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %s to %s", start, end)
+	}
+
+	if got := FiscalYearLabel(2025, time.April); got != "2025-26" {
+		t.Errorf("got %q, want 2025-26", got)
+	}
+	if got := FiscalYearLabel(2099, time.April); got != "2099-00" {
+		t.Errorf("got %q, want 2099-00", got)
+	}
+	if got := FiscalYearLabel(2025, time.January); got != "2025" {
+		t.Errorf("got %q, want 2025", got)
+	}
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type cursor struct {
+	Values   []string `json:"v"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+// EncodeCursor makes the opaque cursor of a row from its sort values
+func EncodeCursor(values []string, backward bool) string {
+	raw, _ := json.Marshal(cursor{Values: values, Backward: backward})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor reads a cursor made by EncodeCursor
+func DecodeCursor(s string) ([]string, bool, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || len(c.Values) == 0 {
+		return nil, false, ErrInvalidCursor
+	}
+	return c.Values, c.Backward, nil
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		values   []string
+		backward bool
+	}{
+		{[]string{"2024-01-15", "8c5d6f1e-3b1a-4c7e-9f0a-1d2e3f4a5b6c"}, false},
+		{[]string{"Dell, \"Latitude\" 5440/ü", ""}, true},
+	}
+	for _, tt := range tests {
+		c := EncodeCursor(tt.values, tt.backward)
+		values, backward, err := DecodeCursor(c)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", c, err)
+			continue
+		}
+		if !reflect.DeepEqual(values, tt.values) || backward != tt.backward {
+			t.Errorf("got %v %v, want %v %v", values, backward, tt.values, tt.backward)
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	for _, c := range []string{
+		"",
+		"not base64!",
+		encode("not json"),
+		encode(`{"v":[]}`),
+		encode(`{"b":true}`),
+		encode(`{"v":"a"}`),
+		base64.StdEncoding.EncodeToString([]byte(`{"v":["a"]}`)),
+	} {
+		if _, _, err := DecodeCursor(c); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%q: got %v, want ErrInvalidCursor", c, err)
+		}
+	}
+}
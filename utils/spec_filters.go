@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"storex/models"
+	"strconv"
+	"strings"
+)
+
+var specFilterPattern = regexp.MustCompile(`^\s*([a-z][a-z0-9_]*)\s*(?:(>=|<=|!=|=|>|<)\s*(.+?)|\s(?i:between)\s+(\S+)\s+(?i:and)\s+(\S+))\s*$`)
+
+// ParseSpecFilter reads a filter like ram_gb>=16, storage_type=SSD|NVMe or
+// screen_size_inch between 24 and 27, the values are checked by ResolveSpecFilter
+func ParseSpecFilter(expr string) (models.SpecFilter, error) {
+	match := specFilterPattern.FindStringSubmatch(expr)
+	if match == nil {
+		return models.SpecFilter{}, fmt.Errorf("%w: cannot read spec filter %q", ErrInvalidSpecs, expr)
+	}
+
+	filter := models.SpecFilter{Field: match[1], Op: match[2]}
+	if filter.Op == "" {
+		filter.Op = "between"
+		filter.Values = []string{match[4], match[5]}
+		return filter, nil
+	}
+	for _, v := range strings.Split(match[3], "|") {
+		filter.Values = append(filter.Values, strings.TrimSpace(v))
+	}
+	if len(filter.Values) > 1 && filter.Op != "=" && filter.Op != "!=" {
+		return models.SpecFilter{}, fmt.Errorf("%w: only = and != take several values in %q", ErrInvalidSpecs, expr)
+	}
+	return filter, nil
+}
+
+// ResolveSpecFilter checks a filter against the spec fields of the asset types it may match.
+// The field has to be of the same kind on every type carrying it, values are normalised to
+// how the specs store them.
+func ResolveSpecFilter(filter *models.SpecFilter, defs []models.AssetTypeDefinition) error {
+	var fields []models.SpecField
+	for _, def := range defs {
+		idx := slices.IndexFunc(def.Fields, func(f models.SpecField) bool { return f.Name == filter.Field })
+		if idx < 0 {
+			continue
+		}
+		fields = append(fields, def.Fields[idx])
+		filter.AssetTypes = append(filter.AssetTypes, def.Name)
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("%w: no asset type has a spec field %q", ErrInvalidSpecs, filter.Field)
+	}
+
+	kind := specKind(fields[0].Type)
+	for _, field := range fields[1:] {
+		if specKind(field.Type) != kind {
+			return fmt.Errorf("%w: %s differs in type between %v, filter by asset_type", ErrInvalidSpecs, filter.Field, filter.AssetTypes)
+		}
+	}
+
+	switch kind {
+	case "number":
+		filter.Numeric = true
+		nums := make([]float64, len(filter.Values))
+		for i, v := range filter.Values {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+				return fmt.Errorf("%w: %s must be compared with a number", ErrInvalidSpecs, filter.Field)
+			}
+			nums[i] = n
+		}
+		if filter.Op == "between" && nums[0] > nums[1] {
+			nums[0], nums[1] = nums[1], nums[0]
+		}
+		for i, n := range nums {
+			filter.Values[i] = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		return nil
+
+	case "bool":
+		if filter.Op != "=" && filter.Op != "!=" {
+			return fmt.Errorf("%w: %s can only be compared with = or !=", ErrInvalidSpecs, filter.Field)
+		}
+		for i, v := range filter.Values {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("%w: %s must be compared with true or false", ErrInvalidSpecs, filter.Field)
+			}
+			filter.Values[i] = strconv.FormatBool(b)
+		}
+		return nil
+
+	default:
+		if filter.Op != "=" && filter.Op != "!=" {
+			return fmt.Errorf("%w: %s can only be compared with = or !=", ErrInvalidSpecs, filter.Field)
+		}
+		for _, v := range filter.Values {
+			allowed := slices.ContainsFunc(fields, func(f models.SpecField) bool {
+				return len(f.Enum) == 0 || slices.Contains(f.Enum, v)
+			})
+			if !allowed {
+				return fmt.Errorf("%w: %q is not a value of %s", ErrInvalidSpecs, v, filter.Field)
+			}
+		}
+		return nil
+	}
+}
+
+// specKind groups the spec field types that compare the same way
+func specKind(fieldType string) string {
+	if fieldType == "int" || fieldType == "number" {
+		return "number"
+	}
+	return fieldType
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"storex/models"
+	"testing"
+)
+
+func TestParseSpecFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want models.SpecFilter
+	}{
+		{"ram_gb>=16", models.SpecFilter{Field: "ram_gb", Op: ">=", Values: []string{"16"}}},
+		{" ram_gb < 16 ", models.SpecFilter{Field: "ram_gb", Op: "<", Values: []string{"16"}}},
+		{"storage_type=SSD|NVMe", models.SpecFilter{Field: "storage_type", Op: "=", Values: []string{"SSD", "NVMe"}}},
+		{"storage_type!=SSD | HDD", models.SpecFilter{Field: "storage_type", Op: "!=", Values: []string{"SSD", "HDD"}}},
+		{"cpu=Core i7", models.SpecFilter{Field: "cpu", Op: "=", Values: []string{"Core i7"}}},
+		{"screen_size_inch between 24 and 27", models.SpecFilter{Field: "screen_size_inch", Op: "between", Values: []string{"24", "27"}}},
+		{"screen_size_inch BETWEEN 27 AND 24", models.SpecFilter{Field: "screen_size_inch", Op: "between", Values: []string{"27", "24"}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSpecFilter(tt.expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseSpecFilterRejects(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"ram_gb",
+		"RAM>16",
+		"ram_gb>16|32",
+		"screen_size_inch between 24",
+		"screen_size_inch between 24 and",
+	} {
+		if _, err := ParseSpecFilter(expr); !errors.Is(err, ErrInvalidSpecs) {
+			t.Errorf("%q: got %v, want ErrInvalidSpecs", expr, err)
+		}
+	}
+}
+
+var filterDefs = []models.AssetTypeDefinition{
+	{Name: "laptop", Fields: laptopFields},
+	{Name: "monitor", Fields: []models.SpecField{
+		{Name: "screen_size_inch", Type: "int"},
+		{Name: "touchscreen", Type: "bool"},
+		{Name: "panel", Type: "string"},
+	}},
+	{Name: "phone", Fields: []models.SpecField{
+		{Name: "storage_type", Type: "string", Enum: []string{"eMMC", "UFS"}},
+		{Name: "cpu", Type: "int"},
+	}},
+}
+
+func TestResolveSpecFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want models.SpecFilter
+	}{
+		{"ram_gb>=16.0", models.SpecFilter{Field: "ram_gb", Op: ">=", Values: []string{"16"}, Numeric: true, AssetTypes: []string{"laptop"}}},
+		{"ram_gb=1e1", models.SpecFilter{Field: "ram_gb", Op: "=", Values: []string{"10"}, Numeric: true, AssetTypes: []string{"laptop"}}},
+		// int and number fields compare alike across types
+		{"screen_size_inch between 27 and 23.8", models.SpecFilter{Field: "screen_size_inch", Op: "between", Values: []string{"23.8", "27"}, Numeric: true, AssetTypes: []string{"laptop", "monitor"}}},
+		{"screen_size_inch between 24 and 24", models.SpecFilter{Field: "screen_size_inch", Op: "between", Values: []string{"24", "24"}, Numeric: true, AssetTypes: []string{"laptop", "monitor"}}},
+		{"touchscreen=TRUE", models.SpecFilter{Field: "touchscreen", Op: "=", Values: []string{"true"}, AssetTypes: []string{"laptop", "monitor"}}},
+		{"touchscreen!=0|t", models.SpecFilter{Field: "touchscreen", Op: "!=", Values: []string{"false", "true"}, AssetTypes: []string{"laptop", "monitor"}}},
+		// a value allowed by one of the types carrying the field is enough
+		{"storage_type=SSD|UFS", models.SpecFilter{Field: "storage_type", Op: "=", Values: []string{"SSD", "UFS"}, AssetTypes: []string{"laptop", "phone"}}},
+		{"panel=IPS", models.SpecFilter{Field: "panel", Op: "=", Values: []string{"IPS"}, AssetTypes: []string{"monitor"}}},
+	}
+	for _, tt := range tests {
+		filter, err := ParseSpecFilter(tt.expr)
+		if err == nil {
+			err = ResolveSpecFilter(&filter, filterDefs)
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(filter, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.expr, filter, tt.want)
+		}
+	}
+}
+
+func TestResolveSpecFilterRejects(t *testing.T) {
+	for _, expr := range []string{
+		"gpu=none",                             // no type has the field
+		"cpu=i7",                               // string on laptops, int on phones
+		"ram_gb>lots",                          // not a number
+		"ram_gb>=",                             // read as > with the value "="
+		"ram_gb>NaN",                           // not a finite number
+		"ram_gb<Inf",                           // not a finite number
+		"screen_size_inch between -Inf and 27", // not a finite number
+		"touchscreen>true",                     // bools only compare for equality
+		"touchscreen=yes",                      // not a bool
+		"storage_type>SSD",                     // strings only compare for equality
+		"storage_type=tape",                    // outside every enum
+	} {
+		filter, err := ParseSpecFilter(expr)
+		if err != nil {
+			t.Errorf("%q: unexpected parse error %v", expr, err)
+			continue
+		}
+		if err := ResolveSpecFilter(&filter, filterDefs); !errors.Is(err, ErrInvalidSpecs) {
+			t.Errorf("%q: got %v, want ErrInvalidSpecs", expr, err)
+		}
+	}
+}
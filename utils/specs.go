@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"storex/models"
+)
+
+var ErrInvalidSpecs = errors.New("invalid specs")
+
+var specFieldTypes = []string{"string", "int", "number", "bool"}
+
+var identifierPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// IsValidIdentifier checks names used for asset types and spec fields
+func IsValidIdentifier(name string) bool {
+	return identifierPattern.MatchString(name)
+}
+
+// SpecsToMap re-marshals the raw specs of a request into a generic map
+func SpecsToMap(specs interface{}) (map[string]interface{}, error) {
+	if specs == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	specsBytes, err := json.Marshal(specs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-marshal specs: %w", err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(specsBytes, &m); err != nil {
+		return nil, fmt.Errorf("%w: specs must be an object", ErrInvalidSpecs)
+	}
+	return m, nil
+}
+
+// ValidateSpecs checks specs against the fields of an asset type and returns the cleaned values.
+// When partial is set (updates) required fields may be left out and null values are dropped.
+func ValidateSpecs(fields []models.SpecField, specs map[string]interface{}, partial bool) (map[string]interface{}, error) {
+	cleaned := map[string]interface{}{}
+
+	for key := range specs {
+		if !slices.ContainsFunc(fields, func(f models.SpecField) bool { return f.Name == key }) {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSpecs, key)
+		}
+	}
+
+	for _, field := range fields {
+		val, ok := specs[field.Name]
+		if !ok || val == nil {
+			if field.Required && !partial {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidSpecs, field.Name)
+			}
+			continue
+		}
+
+		v, err := ValidateSpecValue(field, val)
+		if err != nil {
+			return nil, err
+		}
+		cleaned[field.Name] = v
+	}
+	return cleaned, nil
+}
+
+// ValidateSpecValue checks a single value against its field definition
+func ValidateSpecValue(field models.SpecField, val interface{}) (interface{}, error) {
+	switch field.Type {
+	case "string":
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a string", ErrInvalidSpecs, field.Name)
+		}
+		if len(field.Enum) > 0 && !slices.Contains(field.Enum, s) {
+			return nil, fmt.Errorf("%w: %s must be one of %v", ErrInvalidSpecs, field.Name, field.Enum)
+		}
+		return s, nil
+
+	case "int", "number":
+		n, ok := val.(float64)
+		if !ok || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidSpecs, field.Name)
+		}
+		if field.Type == "int" && n != math.Trunc(n) {
+			return nil, fmt.Errorf("%w: %s must be a whole number", ErrInvalidSpecs, field.Name)
+		}
+		if field.Min != nil && n < *field.Min {
+			return nil, fmt.Errorf("%w: %s must be at least %v", ErrInvalidSpecs, field.Name, *field.Min)
+		}
+		if field.Max != nil && n > *field.Max {
+			return nil, fmt.Errorf("%w: %s must be at most %v", ErrInvalidSpecs, field.Name, *field.Max)
+		}
+		return n, nil
+
+	case "bool":
+		b, ok := val.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be true or false", ErrInvalidSpecs, field.Name)
+		}
+		return b, nil
+
+	default:
+		return nil, fmt.Errorf("%w: field %s has unsupported type %q", ErrInvalidSpecs, field.Name, field.Type)
+	}
+}
+
+// MergeSpecs returns the effective specs of an asset, its own values on top of the model defaults
+func MergeSpecs(defaults, own map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults)+len(own))
+	for key, val := range defaults {
+		merged[key] = val
+	}
+	for key, val := range own {
+		merged[key] = val
+	}
+	return merged
+}
+
+// SpecOverrides drops the values equal to the model defaults, leaving what an asset has to store itself
+func SpecOverrides(defaults, specs map[string]interface{}) map[string]interface{} {
+	overrides := map[string]interface{}{}
+	for key, val := range specs {
+		if def, ok := defaults[key]; ok && reflect.DeepEqual(def, val) {
+			continue
+		}
+		overrides[key] = val
+	}
+	return overrides
+}
+
+// SpecSources tells for every effective spec value whether it is inherited from the model,
+// overrides a model default or is set on the asset only
+func SpecSources(defaults, own map[string]interface{}) map[string]string {
+	sources := map[string]string{}
+	for key := range defaults {
+		sources[key] = "inherited"
+	}
+	for key := range own {
+		if _, ok := defaults[key]; ok {
+			sources[key] = "overridden"
+		} else {
+			sources[key] = "own"
+		}
+	}
+	return sources
+}
+
+// ValidateSpecFields checks a field list submitted through the asset type admin API
+func ValidateSpecFields(fields []models.SpecField) error {
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if !IsValidIdentifier(field.Name) {
+			return fmt.Errorf("invalid field name %q", field.Name)
+		}
+		if seen[field.Name] {
+			return fmt.Errorf("duplicate field %q", field.Name)
+		}
+		seen[field.Name] = true
+
+		if !slices.Contains(specFieldTypes, field.Type) {
+			return fmt.Errorf("field %s: type must be one of %v", field.Name, specFieldTypes)
+		}
+		if len(field.Enum) > 0 && field.Type != "string" {
+			return fmt.Errorf("field %s: enum is only allowed on string fields", field.Name)
+		}
+		if (field.Min != nil || field.Max != nil) && field.Type != "int" && field.Type != "number" {
+			return fmt.Errorf("field %s: min/max are only allowed on numeric fields", field.Name)
+		}
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			return fmt.Errorf("field %s: min is greater than max", field.Name)
+		}
+	}
+	return nil
+}
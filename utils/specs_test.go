@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"storex/models"
+	"testing"
+)
+
+var laptopFields = []models.SpecField{
+	{Name: "ram_gb", Type: "int", Required: true, Min: ptr(1.0), Max: ptr(512.0)},
+	{Name: "screen_size_inch", Type: "number"},
+	{Name: "storage_type", Type: "string", Enum: []string{"HDD", "SSD", "NVMe"}},
+	{Name: "touchscreen", Type: "bool"},
+	{Name: "cpu", Type: "string"},
+}
+
+func TestValidateSpecs(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   map[string]interface{}
+		partial bool
+		want    map[string]interface{}
+	}{
+		{"full", map[string]interface{}{"ram_gb": 16.0, "storage_type": "SSD", "touchscreen": false},
+			false, map[string]interface{}{"ram_gb": 16.0, "storage_type": "SSD", "touchscreen": false}},
+		{"partial without required", map[string]interface{}{"cpu": "i7"}, true, map[string]interface{}{"cpu": "i7"}},
+		{"partial drops nulls", map[string]interface{}{"cpu": nil, "screen_size_inch": 14.5}, true, map[string]interface{}{"screen_size_inch": 14.5}},
+		{"bounds are inclusive", map[string]interface{}{"ram_gb": 512.0}, false, map[string]interface{}{"ram_gb": 512.0}},
+	}
+	for _, tt := range tests {
+		got, err := ValidateSpecs(laptopFields, tt.specs, tt.partial)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSpecsRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   map[string]interface{}
+		partial bool
+	}{
+		{"missing required", map[string]interface{}{"cpu": "i7"}, false},
+		{"null required", map[string]interface{}{"ram_gb": nil}, false},
+		{"unknown field", map[string]interface{}{"ram_gb": 16.0, "gpu": "none"}, true},
+		{"fractional int", map[string]interface{}{"ram_gb": 16.5}, true},
+		{"below min", map[string]interface{}{"ram_gb": 0.0}, true},
+		{"above max", map[string]interface{}{"ram_gb": 1024.0}, true},
+		{"number as string", map[string]interface{}{"ram_gb": "16"}, true},
+		{"NaN", map[string]interface{}{"screen_size_inch": math.NaN()}, true},
+		{"infinity", map[string]interface{}{"screen_size_inch": math.Inf(-1)}, true},
+		{"value outside enum", map[string]interface{}{"storage_type": "tape"}, true},
+		{"bool as string", map[string]interface{}{"touchscreen": "true"}, true},
+	}
+	for _, tt := range tests {
+		if _, err := ValidateSpecs(laptopFields, tt.specs, tt.partial); !errors.Is(err, ErrInvalidSpecs) {
+			t.Errorf("%s: got %v, want ErrInvalidSpecs", tt.name, err)
+		}
+	}
+}
+
+func TestSpecsToMap(t *testing.T) {
+	got, err := SpecsToMap(map[string]any{"ram_gb": 16})
+	if err != nil || !reflect.DeepEqual(got, map[string]interface{}{"ram_gb": 16.0}) {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	if got, err := SpecsToMap(nil); err != nil || len(got) != 0 {
+		t.Errorf("nil specs got %v, %v", got, err)
+	}
+	if _, err := SpecsToMap([]int{1}); !errors.Is(err, ErrInvalidSpecs) {
+		t.Errorf("array specs got %v, want ErrInvalidSpecs", err)
+	}
+}
+
+func TestMergeSpecs(t *testing.T) {
+	defaults := map[string]interface{}{"ram_gb": 16.0, "cpu": "i5"}
+	own := map[string]interface{}{"ram_gb": 32.0, "touchscreen": true}
+
+	want := map[string]interface{}{"ram_gb": 32.0, "cpu": "i5", "touchscreen": true}
+	if got := MergeSpecs(defaults, own); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if defaults["ram_gb"] != 16.0 {
+		t.Error("MergeSpecs changed the defaults")
+	}
+	if got := MergeSpecs(nil, nil); got == nil || len(got) != 0 {
+		t.Errorf("got %v, want an empty map", got)
+	}
+}
+
+func TestSpecOverrides(t *testing.T) {
+	defaults := map[string]interface{}{"ram_gb": 16.0, "cpu": "i5", "touchscreen": false}
+	specs := map[string]interface{}{"ram_gb": 16.0, "cpu": "i7", "touchscreen": false, "screen_size_inch": 14.0}
+
+	want := map[string]interface{}{"cpu": "i7", "screen_size_inch": 14.0}
+	if got := SpecOverrides(defaults, specs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// storing the overrides and merging them back gives the specs again
+	if got := MergeSpecs(defaults, SpecOverrides(defaults, specs)); !reflect.DeepEqual(got, specs) {
+		t.Errorf("round trip got %v, want %v", got, specs)
+	}
+}
+
+func TestSpecSources(t *testing.T) {
+	defaults := map[string]interface{}{"ram_gb": 16.0, "cpu": "i5"}
+	own := map[string]interface{}{"cpu": "i7", "touchscreen": true}
+
+	want := map[string]string{"ram_gb": "inherited", "cpu": "overridden", "touchscreen": "own"}
+	if got := SpecSources(defaults, own); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestValidateSpecFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []models.SpecField
+		ok     bool
+	}{
+		{"valid", laptopFields, true},
+		{"bad name", []models.SpecField{{Name: "RAM", Type: "int"}}, false},
+		{"duplicate", []models.SpecField{{Name: "cpu", Type: "string"}, {Name: "cpu", Type: "string"}}, false},
+		{"unknown type", []models.SpecField{{Name: "cpu", Type: "text"}}, false},
+		{"enum on number", []models.SpecField{{Name: "ram_gb", Type: "int", Enum: []string{"8"}}}, false},
+		{"min on string", []models.SpecField{{Name: "cpu", Type: "string", Min: ptr(1.0)}}, false},
+		{"min above max", []models.SpecField{{Name: "ram_gb", Type: "int", Min: ptr(8.0), Max: ptr(4.0)}}, false},
+	}
+	for _, tt := range tests {
+		err := ValidateSpecFields(tt.fields)
+		if tt.ok != (err == nil) {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+}
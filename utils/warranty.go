@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"storex/models"
+	"time"
+)
+
+// WarrantyStatus works out the warranty state of an asset relative to now
+func WarrantyStatus(start *time.Time, exp *time.Time, now time.Time) models.WarrantyState {
+	if exp == nil {
+		return models.WarrantyState{Status: "unknown"}
+	}
+
+	if start != nil && now.Before(*start) {
+		return models.WarrantyState{Status: "not_started"}
+	}
+
+	if now.After(*exp) {
+		return models.WarrantyState{Status: "expired"}
+	}
+
+	days := int(exp.Sub(now).Hours() / 24)
+	return models.WarrantyState{Status: "active", DaysRemaining: &days}
+}